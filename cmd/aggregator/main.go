@@ -1,53 +1,339 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	_ "subscription-aggregator/docs"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/config"
 	"subscription-aggregator/internal/handler"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/middleware"
+	"subscription-aggregator/internal/outbox"
+	"subscription-aggregator/internal/ratelimit"
 	"subscription-aggregator/internal/repository"
+	"subscription-aggregator/internal/stream"
+	"subscription-aggregator/internal/tracing"
+	"subscription-aggregator/internal/webhook"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 func main() {
-	log.Println("loading .env file...")
-	err := godotenv.Load()
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("failed to load .env file: %v", err)
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
 	}
-	log.Println(".env file loaded")
 
-	log.Println("initializing database...")
-	repository.InitAndMigrateDB()
+	l, err := logger.New(os.Stdout, cfg.Log.Level)
+	if err != nil {
+		slog.Error("failed to configure logger", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(l)
+	slog.Info("configuration loaded", "config", cfg)
+
+	args := os.Args[1:]
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	switch args[0] {
+	case "serve":
+		serve(cfg)
+	case "migrate":
+		os.Exit(runMigrate(cfg.DB, args[1:]))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+}
+
+const usage = `Usage:
+  aggregator [serve]          start the HTTP server (default)
+  aggregator migrate up       apply all pending migrations
+  aggregator migrate down N   roll back the last N migrations
+  aggregator migrate status   show the current and pending versions
+  aggregator migrate force V  mark version V as applied without running SQL
+`
+
+func serve(cfg config.Config) {
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		slog.Error("failed to configure tracing", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("tracing configured", "exporter", cfg.Tracing.Exporter)
+
+	slog.Info("initializing database")
+	repository.InitDB(cfg.DB)
+
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 10*time.Second)
+	err = repository.CheckMigrations(checkCtx)
+	cancelCheck()
+	if err != nil {
+		slog.Error("refusing to serve: database schema is not up to date, run `aggregator migrate up`", "error", err)
+		os.Exit(1)
+	}
+
+	eventHub := stream.NewHub(cfg.Events)
+	handler.EventStream = eventHub
+
+	if cfg.Features.StrictDuplicates {
+		handler.StrictDuplicates = true
+		slog.Info("strict duplicates mode enabled")
+	}
 
-	r := gin.Default()
+	if cfg.Webhooks.AllowPrivateTargets {
+		handler.AllowPrivateWebhookTargets = true
+		slog.Warn("webhooks to private and loopback addresses are allowed")
+	}
+
+	r := gin.New()
+	// Client IPs come from X-Forwarded-For only when the direct peer is one of
+	// the configured proxies; otherwise anyone could pick their own rate limit
+	// bucket.
+	if err := r.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		slog.Error("invalid trusted proxies", "error", err)
+		os.Exit(1)
+	}
+	r.Use(middleware.Tracing(cfg.Tracing.ServiceName), middleware.RequestLogger(), middleware.Metrics(), gin.Recovery())
+
+	slog.Info("registering routes")
+
+	verifier, err := auth.NewVerifier(cfg.Auth)
+	if err != nil {
+		slog.Error("failed to configure authentication", "error", err)
+		os.Exit(1)
+	}
+
+	rateLimits := cfg.RateLimit
+	if !rateLimits.Enabled {
+		slog.Warn("rate limiting disabled")
+		rateLimits = config.RateLimitConfig{}
+	}
+	limiter := ratelimit.NewMemoryStore()
+	ipLimit := middleware.IPRateLimit(limiter, "ip", ratelimit.FromConfig(rateLimits.IP))
 
-	log.Println("registering routes...")
+	api := r.Group("",
+		ipLimit,
+		middleware.Authenticate(verifier, repository.ResolveAPIKey, cfg.Auth.AdminToken),
+		middleware.Tenant(repository.TenantExists, uuid.MustParse(cfg.Tenancy.DefaultTenantID)),
+	)
+	adminOnly := middleware.AdminOnly()
 
-	r.POST("/create", handler.CreateSubscription)
-	r.GET("/read/:id", handler.ReadSubscription)
-	r.PUT("/update/:id", handler.UpdateSubscription)
-	r.DELETE("/delete/:id", handler.DeleteSubscription)
-	r.GET("/list", handler.ListSubscriptions)
-	r.GET("/sum", handler.SumSubscriptionsPrice)
+	defaultLimit := middleware.RateLimit(limiter, "default", ratelimit.FromConfig(rateLimits.Default))
+	standard := api.Group("", defaultLimit)
+	reports := api.Group("", middleware.RateLimit(limiter, "reports", ratelimit.FromConfig(rateLimits.Reports)))
+	bulkOps := api.Group("", middleware.RateLimit(limiter, "bulk", ratelimit.FromConfig(rateLimits.Bulk)))
 
+	standard.POST("/create", handler.CreateSubscription)
+	standard.GET("/read/:id", handler.ReadSubscription)
+	standard.PUT("/update/:id", handler.UpdateSubscription)
+	standard.DELETE("/delete/:id", handler.DeleteSubscription)
+	standard.POST("/restore/:id", handler.RestoreSubscription)
+	standard.GET("/list", handler.ListSubscriptions)
+	standard.GET("/events", handler.StreamEvents)
+	reports.GET("/sum", handler.SumSubscriptionsPrice)
+	bulkOps.POST("/import", handler.ImportSubscriptions)
+	reports.GET("/export", handler.ExportSubscriptions)
+	reports.GET("/duplicates", handler.ListDuplicateSubscriptions)
+	reports.GET("/stats", handler.GetUserStats)
+
+	standard.POST("/budget/create", handler.CreateBudget)
+	standard.GET("/budget/list", handler.ListBudgets)
+	standard.DELETE("/budget/delete/:id", handler.DeleteBudget)
+	reports.GET("/budget/evaluate/:id", handler.EvaluateBudget)
+
+	// The feed is authenticated by its own per-user token so calendar apps can
+	// poll it; it is rate limited by client IP.
+	r.GET("/users/:user_id/calendar.ics", defaultLimit, handler.GetCalendarFeed)
+	standard.POST("/users/:user_id/calendar-token", handler.IssueCalendarToken)
+
+	analytics := reports.Group("/analytics", adminOnly)
+	analytics.GET("/services", handler.GetServiceAnalytics)
+	analytics.GET("/prices", handler.GetPriceAnalytics)
+	analytics.GET("/movements", handler.GetMovementAnalytics)
+	analytics.GET("/cohorts", handler.GetCohortRetention)
+	analytics.GET("/anomalies", handler.GetPriceAnomalies)
+
+	bulk := bulkOps.Group("/bulk", adminOnly)
+	bulk.POST("/create", handler.BulkCreateSubscriptions)
+	bulk.POST("/update", handler.BulkUpdateSubscriptions)
+	bulk.POST("/delete", handler.BulkDeleteSubscriptions)
+
+	apiKeys := standard.Group("/api-keys", adminOnly)
+	apiKeys.POST("/create", handler.CreateAPIKey)
+	apiKeys.GET("/list", handler.ListAPIKeys)
+	apiKeys.POST("/revoke/:id", handler.RevokeAPIKey)
+	apiKeys.POST("/rotate/:id", handler.RotateAPIKey)
+
+	webhooks := standard.Group("/webhooks", adminOnly)
+	webhooks.POST("/create", handler.CreateWebhook)
+	webhooks.GET("/list", handler.ListWebhooks)
+	webhooks.DELETE("/delete/:id", handler.DeleteWebhook)
+	webhooks.GET("/deliveries", handler.ListWebhookDeliveries)
+	webhooks.POST("/redeliver/:id", handler.RedeliverWebhook)
+
+	standard.GET("/tenant/settings", handler.GetTenantSettings)
+	standard.PUT("/tenant/settings", adminOnly, handler.UpdateTenantSettings)
+
+	tenants := standard.Group("/tenants", middleware.PlatformAdminOnly())
+	tenants.POST("/create", handler.CreateTenant)
+	tenants.GET("/list", handler.ListTenants)
+
+	r.GET("/healthz", handler.Healthz)
+	r.GET("/readyz", handler.Readyz)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-		log.Printf("PORT not set in env, using default: %s", port)
+	srv := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.HTTP.Port),
+		Handler:           r,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+	// Metrics are served on their own port so that they can stay off the
+	// public listener.
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("GET /metrics", promhttp.Handler())
+	metricsSrv := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Metrics.Port),
+		Handler:           metricsMux,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+	shutdownTimeout := cfg.HTTP.ShutdownTimeout
+	// Event streams never finish on their own, so end them when draining starts.
+	srv.RegisterOnShutdown(eventHub.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	purger := outbox.NewPurger(cfg.Outbox)
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		eventHub.Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		repository.RefreshMetrics(workerCtx, cfg.Metrics.RefreshInterval)
+	}()
+	go func() {
+		defer workers.Done()
+		purger.Run(workerCtx)
+	}()
+	var publisher outbox.Publisher
+	if cfg.Outbox.Enabled {
+		publisher, err = outbox.NewPublisher(cfg.Outbox)
+		if err != nil {
+			slog.Error("failed to configure outbox publishers", "error", err)
+			os.Exit(1)
+		}
+		relay := outbox.NewRelay(publisher, cfg.Outbox)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			relay.Run(workerCtx)
+		}()
+		slog.Info("outbox relay started", "publishers", cfg.Outbox.Publishers)
 	} else {
-		log.Printf("using port from env: %s", port)
+		slog.Warn("outbox relay disabled, events stay in the outbox", "unpublished_retention", cfg.Outbox.UnpublishedRetention)
+	}
+	if cfg.Webhooks.Enabled {
+		dispatcher := webhook.NewDispatcher(cfg.Webhooks)
+		notifier := webhook.NewRenewalNotifier(cfg.Webhooks.RenewalLead)
+		workers.Add(2)
+		go func() {
+			defer workers.Done()
+			dispatcher.Run(workerCtx)
+		}()
+		go func() {
+			defer workers.Done()
+			notifier.Run(workerCtx)
+		}()
+		slog.Info("webhook delivery started", "poll_interval", cfg.Webhooks.PollInterval)
+	} else {
+		slog.Warn("webhook delivery disabled, deliveries stay queued")
 	}
 
-	log.Printf("starting server on port %s...", port)
-	err = r.Run(":" + port)
-	if err != nil {
-		log.Fatalf("server failed to start: %v", err)
+	serverErr := make(chan error, 2)
+	go func() {
+		slog.Info(
+			"starting server",
+			"port", cfg.HTTP.Port,
+			"read_timeout", srv.ReadTimeout,
+			"write_timeout", srv.WriteTimeout,
+			"idle_timeout", srv.IdleTimeout,
+		)
+		serverErr <- srv.ListenAndServe()
+	}()
+	go func() {
+		slog.Info("starting metrics server", "port", cfg.Metrics.Port)
+		serverErr <- metricsSrv.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("server failed to start", "error", err)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		stop()
+		slog.Info("shutdown signal received, draining in-flight requests", "timeout", shutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	exitCode := 0
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to drain in-flight requests", "error", err)
+		exitCode = 1
+	}
+	if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to stop metrics server", "error", err)
+		exitCode = 1
+	}
+	// Workers finish their in-flight attempts before the pool is closed.
+	stopWorkers()
+	workers.Wait()
+	if closer, ok := publisher.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			slog.Error("failed to close outbox publishers", "error", err)
+			exitCode = 1
+		}
 	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+		exitCode = 1
+	}
+	if err := repository.Close(); err != nil {
+		slog.Error("failed to close database pool", "error", err)
+		exitCode = 1
+	}
+
+	slog.Info("server stopped")
+	os.Exit(exitCode)
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/repository"
+	"syscall"
+)
+
+func runMigrate(cfg config.DBConfig, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	repository.InitDB(cfg)
+	defer repository.Close()
+
+	migrator, err := repository.Migrator()
+	if err != nil {
+		slog.Error("failed to load migrations", "error", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			slog.Info("migration applied", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			slog.Error("migration failed", "error", err)
+			return 1
+		}
+		slog.Info("schema is up to date", "version", migrator.Latest(), "applied", len(applied))
+
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n <= 0 {
+				fmt.Fprintf(os.Stderr, "invalid number of migrations %q\n", args[1])
+				return 2
+			}
+		}
+		reverted, err := migrator.Down(ctx, n)
+		for _, m := range reverted {
+			slog.Info("migration reverted", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			slog.Error("rollback failed", "error", err)
+			return 1
+		}
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			slog.Error("failed to read schema version", "error", err)
+			return 1
+		}
+		if status.Initialized {
+			fmt.Printf("current: %d\n", status.Current)
+		} else {
+			fmt.Println("current: not initialized")
+		}
+		fmt.Printf("latest:  %d\ndirty:   %t\n", status.Latest, status.Dirty)
+		for _, m := range status.Pending {
+			fmt.Printf("pending: %04d_%s\n", m.Version, m.Name)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "force requires a version")
+			return 2
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", args[1])
+			return 2
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			slog.Error("failed to force schema version", "error", err)
+			return 1
+		}
+		slog.Info("schema version forced", "version", version)
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,318 @@
+// Command benchmark seeds the subscriptions table with synthetic rows and
+// reports the query plan and latency distribution of the queries behind the
+// read endpoints. Run it against a throwaway database: seeding inserts
+// millions of rows and -truncate wipes the table first.
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var services = []string{
+	"Netflix", "Spotify", "Yandex Plus", "YouTube Premium", "Apple Music",
+	"Kinopoisk", "Okko", "IVI", "VK Music", "Telegram Premium",
+}
+
+const seedBatch = 100_000
+
+type params struct {
+	tenantID    uuid.UUID
+	userID      uuid.UUID
+	serviceName string
+	from, to    monthyear.MonthYear
+}
+
+// benchCase mirrors the query a handler issues. build must finish with a
+// Find or Scan so it can be both executed and rendered through ToSQL.
+type benchCase struct {
+	name     string
+	endpoint string
+	build    func(tx *gorm.DB, p params) *gorm.DB
+}
+
+var cases = []benchCase{
+	{
+		name:     "list_by_user",
+		endpoint: "GET /list?user_id",
+		build: func(tx *gorm.DB, p params) *gorm.DB {
+			var subs []model.Subscription
+			return tx.Where("user_id = ?", p.userID).Find(&subs)
+		},
+	},
+	{
+		name:     "list_by_user_service",
+		endpoint: "GET /list?user_id&service_name",
+		build: func(tx *gorm.DB, p params) *gorm.DB {
+			var subs []model.Subscription
+			return tx.Where("user_id = ?", p.userID).Where("service_name = ?", p.serviceName).Find(&subs)
+		},
+	},
+	{
+		name:     "list_by_service",
+		endpoint: "GET /list?service_name",
+		build: func(tx *gorm.DB, p params) *gorm.DB {
+			var subs []model.Subscription
+			return tx.Where("service_name = ?", p.serviceName).Find(&subs)
+		},
+	},
+	{
+		name:     "sum",
+		endpoint: "GET /sum",
+		build: func(tx *gorm.DB, p params) *gorm.DB {
+			var sum int
+			return tx.Model(&model.Subscription{}).
+				Select("COALESCE(SUM(price), 0)").
+				Where("user_id = ?", p.userID).
+				Where("LOWER(service_name) = LOWER(?)", p.serviceName).
+				Where("start_date BETWEEN ? AND ?", p.from, p.to).
+				Scan(&sum)
+		},
+	},
+	{
+		name:     "monthly_spend",
+		endpoint: "GET /budget/evaluate/:id",
+		build: func(tx *gorm.DB, p params) *gorm.DB {
+			var spend []repository.MonthlySpend
+			return tx.Raw(`
+				SELECT m.month::date AS month, COALESCE(SUM(s.price), 0) AS total
+				FROM generate_series(?::date, ?::date, interval '1 month') AS m(month)
+				LEFT JOIN subscriptions s
+					ON s.tenant_id = ?
+					AND s.user_id = ?
+					AND s.deleted_at IS NULL
+					AND s.start_date <= m.month
+					AND (s.end_date IS NULL OR s.end_date >= m.month)
+					AND LOWER(s.service_name) = LOWER(?)
+				GROUP BY m.month
+				ORDER BY m.month`,
+				p.from, p.to, p.tenantID, p.userID, p.serviceName,
+			).Scan(&spend)
+		},
+	},
+}
+
+func main() {
+	rows := flag.Int("rows", 1_000_000, "number of subscriptions to seed")
+	users := flag.Int("users", 50_000, "number of distinct users the seeded rows are spread over")
+	iterations := flag.Int("iterations", 200, "queries to time per case")
+	seed := flag.Bool("seed", true, "insert -rows synthetic subscriptions before measuring")
+	truncate := flag.Bool("truncate", false, "empty the subscriptions table before seeding")
+	flag.Parse()
+
+	for _, check := range []struct {
+		ok      bool
+		message string
+	}{
+		{*rows >= 0, "-rows must not be negative"},
+		{*users > 0, "-users must be positive"},
+		{*iterations > 0, "-iterations must be positive"},
+	} {
+		if !check.ok {
+			fmt.Fprintln(os.Stderr, check.message)
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	l, err := logger.New(os.Stderr, "warn")
+	if err != nil {
+		slog.Error("failed to configure logger", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(l)
+
+	// Queries run as an admin of the default tenant, the way handlers see them.
+	tenantID := uuid.MustParse(cfg.Tenancy.DefaultTenantID)
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{Admin: true, TenantID: tenantID, TenantBound: true})
+	repository.InitDB(cfg.DB)
+	defer repository.Close()
+
+	if err := repository.CheckMigrations(ctx); err != nil {
+		slog.Error("database schema is not up to date, run `aggregator migrate up`", "error", err)
+		os.Exit(1)
+	}
+
+	if *truncate {
+		fmt.Println("truncating subscriptions")
+		if err := repository.DB.Exec("TRUNCATE subscriptions RESTART IDENTITY").Error; err != nil {
+			slog.Error("truncate failed", "error", err)
+			os.Exit(1)
+		}
+	}
+	if *seed {
+		if err := seedSubscriptions(ctx, tenantID, *rows, *users); err != nil {
+			slog.Error("seeding failed", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	randomParams := func() params {
+		from := monthyear.MonthYear{Time: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)}.AddMonths(rnd.Intn(60))
+		return params{
+			tenantID:    tenantID,
+			userID:      benchUserID(rnd.Intn(*users)),
+			serviceName: services[rnd.Intn(len(services))],
+			from:        from,
+			to:          from.AddMonths(11),
+		}
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(out, "case\tendpoint\tp50\tp95\tp99\tmax\tmean")
+	plans := make([]string, 0, len(cases))
+
+	for _, bc := range cases {
+		latencies := make([]time.Duration, 0, *iterations)
+		for i := 0; i < *iterations; i++ {
+			p := randomParams()
+			started := time.Now()
+			if err := bc.build(repository.DB.WithContext(ctx), p).Error; err != nil {
+				slog.Error("query failed", "case", bc.name, "error", err)
+				os.Exit(1)
+			}
+			latencies = append(latencies, time.Since(started))
+		}
+
+		plan, err := explain(ctx, bc, randomParams())
+		if err != nil {
+			slog.Error("explain failed", "case", bc.name, "error", err)
+			os.Exit(1)
+		}
+		plans = append(plans, fmt.Sprintf("== %s (%s)\n%s", bc.name, bc.endpoint, plan))
+
+		s := summarize(latencies)
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", bc.name, bc.endpoint, s.p50, s.p95, s.p99, s.max, s.mean)
+	}
+
+	out.Flush()
+
+	fmt.Println()
+	fmt.Println(strings.Join(plans, "\n"))
+}
+
+func seedSubscriptions(ctx context.Context, tenantID uuid.UUID, rows, users int) error {
+	started := time.Now()
+	for offset := 0; offset < rows; offset += seedBatch {
+		upto := offset + seedBatch
+		if upto > rows {
+			upto = rows
+		}
+
+		// Rows are derived from the series number so that reruns produce the
+		// same distribution; roughly a third of them have an end date.
+		err := repository.DB.WithContext(ctx).Exec(`
+			INSERT INTO subscriptions (created_at, updated_at, tenant_id, service_name, price, user_id, start_date, end_date)
+			SELECT now(), now(), @tenant_id::uuid,
+				(@services::text[])[1 + g % @service_count::int],
+				100 + (g * 37) % 1900,
+				md5('bench-user-' || (g % @users::int))::uuid,
+				(date '2020-01-01' + ((g * 7) % 72) * interval '1 month')::date,
+				CASE WHEN g % 3 = 0
+					THEN (date '2020-01-01' + ((g * 7) % 72 + 1 + g % 24) * interval '1 month')::date
+				END
+			FROM generate_series(@from::int, @to::int) AS g`,
+			map[string]interface{}{
+				"tenant_id":     tenantID,
+				"services":      "{" + strings.Join(quoteAll(services), ",") + "}",
+				"service_count": len(services),
+				"users":         users,
+				"from":          offset + 1,
+				"to":            upto,
+			},
+		).Error
+		if err != nil {
+			return err
+		}
+		fmt.Printf("seeded %d/%d rows\n", upto, rows)
+	}
+
+	if err := repository.DB.WithContext(ctx).Exec("ANALYZE subscriptions").Error; err != nil {
+		return err
+	}
+	fmt.Printf("seeding finished in %s\n\n", time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+func explain(ctx context.Context, bc benchCase, p params) (string, error) {
+	query := repository.DB.WithContext(ctx).ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return bc.build(tx, p)
+	})
+
+	rows, err := repository.DB.WithContext(ctx).Raw("EXPLAIN (ANALYZE, BUFFERS) " + query).Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		plan.WriteString(line)
+		plan.WriteByte('\n')
+	}
+	return plan.String(), rows.Err()
+}
+
+// benchUserID matches md5('bench-user-' || n)::uuid in the seed query.
+func benchUserID(n int) uuid.UUID {
+	return uuid.UUID(md5.Sum([]byte(fmt.Sprintf("bench-user-%d", n))))
+}
+
+type summary struct {
+	p50, p95, p99, max, mean time.Duration
+}
+
+func summarize(latencies []time.Duration) summary {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	at := func(q float64) time.Duration {
+		return latencies[int(q*float64(len(latencies)-1))].Round(time.Microsecond)
+	}
+	return summary{
+		p50:  at(0.50),
+		p95:  at(0.95),
+		p99:  at(0.99),
+		max:  latencies[len(latencies)-1].Round(time.Microsecond),
+		mean: (total / time.Duration(len(latencies))).Round(time.Microsecond),
+	}
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = `"` + v + `"`
+	}
+	return quoted
+}
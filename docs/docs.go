@@ -15,31 +15,61 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/create": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/analytics/anomalies": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Создание подписки",
+                "summary": "Поиск подписок, цена которых сильно отличается от медианы по подпискам того же сервиса, активным в том же месяце",
                 "parameters": [
                     {
-                        "description": "Данные подписки",
-                        "name": "subscription",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/swagger.SubscriptionExample"
-                        }
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "default": "06-2025",
+                        "description": "Начало периода в формате MM-YYYY",
+                        "name": "period_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "08-2025",
+                        "description": "Конец периода в формате MM-YYYY",
+                        "name": "period_end",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "default": 5,
+                        "description": "Во сколько раз цена должна отличаться от медианы",
+                        "name": "factor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 3,
+                        "description": "Минимальное количество подписок для расчёта медианы",
+                        "name": "min_sample",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/swagger.MessageResponse"
+                            "$ref": "#/definitions/swagger.PriceAnomalyReportResponse"
                         }
                     },
                     "400": {
@@ -48,6 +78,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/swagger.ErrorResponse400"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -57,27 +105,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/delete/{id}": {
-            "delete": {
+        "/analytics/cohorts": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Удалить подписку по ID",
+                "summary": "Когортный отчёт удержания: доля подписок каждого месяца старта, активных через 1, 3, 6 и 12 месяцев",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "default": "01-2025",
+                        "description": "Первый месяц когорт в формате MM-YYYY",
+                        "name": "period_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "08-2025",
+                        "description": "Последний месяц когорт в формате MM-YYYY",
+                        "name": "period_end",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/swagger.MessageResponse"
+                            "$ref": "#/definitions/swagger.CohortReportResponse"
                         }
                     },
                     "400": {
@@ -86,10 +160,22 @@ const docTemplate = `{
                             "$ref": "#/definitions/swagger.ErrorResponse400"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
                         }
                     },
                     "500": {
@@ -101,26 +187,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/list": {
+        "/analytics/movements": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Получение списка подписок (есть фильтрация по ID пользователя и по названию сервиса)",
+                "summary": "Новые и завершившиеся (отток) подписки по месяцам периода",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "11111111-1111-1111-1111-111111111111",
-                        "description": "ID пользователя",
-                        "name": "user_id",
-                        "in": "query"
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
                     },
                     {
                         "type": "string",
-                        "default": "Netflix",
-                        "description": "Название сервиса",
-                        "name": "service_name",
-                        "in": "query"
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "default": "06-2025",
+                        "description": "Начало периода в формате MM-YYYY",
+                        "name": "period_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "08-2025",
+                        "description": "Конец периода в формате MM-YYYY",
+                        "name": "period_end",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -129,14 +229,32 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/swagger.SubscriptionResponse"
+                                "$ref": "#/definitions/swagger.MonthMovementResponse"
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
                         }
                     },
                     "500": {
@@ -148,19 +266,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/read/{id}": {
+        "/analytics/prices": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Получить данные подписки по ID",
+                "summary": "Средняя, минимальная и максимальная цена подписки по каждому сервису за период",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "default": "06-2025",
+                        "description": "Начало периода в формате MM-YYYY",
+                        "name": "period_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "08-2025",
+                        "description": "Конец периода в формате MM-YYYY",
+                        "name": "period_end",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -168,7 +306,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/swagger.SubscriptionResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.ServicePriceStatsResponse"
+                            }
                         }
                     },
                     "400": {
@@ -177,10 +318,22 @@ const docTemplate = `{
                             "$ref": "#/definitions/swagger.ErrorResponse400"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
                         }
                     },
                     "500": {
@@ -192,28 +345,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/sum": {
+        "/analytics/services": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Получение суммы стоимости всех подписок за выбранный период по ID пользователя и имени сервиса",
+                "summary": "Количество подписчиков и выручка по каждому сервису за каждый месяц периода",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "11111111-1111-1111-1111-111111111111",
-                        "description": "ID пользователя",
-                        "name": "user_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
                     },
                     {
                         "type": "string",
-                        "default": "Netflix",
-                        "description": "Название сервиса",
-                        "name": "service_name",
-                        "in": "query",
-                        "required": true
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
                     },
                     {
                         "type": "string",
@@ -236,7 +385,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/swagger.SumResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.ServiceMonthStatsResponse"
+                            }
                         }
                     },
                     "400": {
@@ -245,6 +397,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/swagger.ErrorResponse400"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -254,31 +424,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/update/{id}": {
-            "put": {
+        "/api-keys/create": {
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Обновить подписку по ID",
+                "summary": "Создание API-ключа для сервисных клиентов (ключ возвращается один раз)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
                     },
                     {
-                        "description": "Новые данные подписки",
-                        "name": "subscription",
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Параметры ключа",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/swagger.UpdateSubscriptionExample"
+                            "$ref": "#/definitions/swagger.APIKeyExample"
                         }
                     }
                 ],
@@ -286,7 +460,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/swagger.MessageResponse"
+                            "$ref": "#/definitions/swagger.APIKeyIssuedResponse"
                         }
                     },
                     "400": {
@@ -295,10 +469,22 @@ const docTemplate = `{
                             "$ref": "#/definitions/swagger.ErrorResponse400"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
                         }
                     },
                     "500": {
@@ -309,52 +495,3150 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "swagger.ErrorResponse400": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "invalid {id/request/json}"
-                }
-            }
-        },
-        "swagger.ErrorResponse404": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "record not found in db"
-                }
-            }
-        },
-        "swagger.ErrorResponse500": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "failed to {create/find/update/delete} record in db"
-                }
-            }
         },
-        "swagger.MessageResponse": {
-            "type": "object",
+        "/api-keys/list": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список API-ключей (без секретов)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.APIKeyResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys/revoke/{id}": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Отзыв API-ключа по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys/rotate/{id}": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ротация API-ключа: выпускается новый секрет, старый перестаёт действовать сразу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.APIKeyIssuedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse409"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/budget/create": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создание бюджета пользователя (общего, по сервису или по категории); warning_threshold по умолчанию 80",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные бюджета",
+                        "name": "budget",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BudgetExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/budget/delete/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удалить бюджет по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID бюджета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/budget/evaluate/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Оценка фактических и прогнозных трат относительно бюджета по месяцам",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID бюджета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "06-2025",
+                        "description": "Начало периода в формате MM-YYYY",
+                        "name": "period_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "12-2025",
+                        "description": "Конец периода в формате MM-YYYY",
+                        "name": "period_end",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BudgetEvaluationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/budget/list": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение списка бюджетов пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.BudgetResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulk/create": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Массовое создание подписок в одной транзакции",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Только проверить, ничего не сохраняя",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Список подписок",
+                        "name": "subscriptions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BulkCreateExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BulkResultResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BulkResultResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulk/delete": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Массовое удаление подписок по списку ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Только посчитать существующие подписки",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Список ID",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BulkDeleteExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BulkResultResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulk/update": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Массовое обновление подписок по фильтру (service_name / user_id), не более 1000 за запрос",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Только посчитать подходящие подписки",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Фильтр и изменения",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BulkUpdateExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.BulkResultResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse409"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/create": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создание подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные подписки",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.SubscriptionExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse409"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/delete/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удалить подписку по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/duplicates": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Поиск вероятных дубликатов подписок пользователя (один сервис, пересекающиеся месяцы, близкая цена)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.DuplicateResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Поток изменений подписок (Server-Sent Events); переподключение продолжает поток с заголовка Last-Event-ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя (для администратора - все пользователи, если не указан)",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "События: id - ID для Last-Event-ID, event - тип, data - JSON события",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse503"
+                        }
+                    }
+                }
+            }
+        },
+        "/export": {
+            "get": {
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "summary": "Выгрузка подписок в CSV, NDJSON или XLSX (фильтры как у /list)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат выгрузки",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Netflix",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Проверка, что процесс жив",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/import": {
+            "post": {
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Импорт подписок из CSV (service_name, price, user_id, start_date, end_date, category, billing_cycle) с пробным режимом и отчётом об ошибках",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV файл с заголовком",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Только проверить строки, ничего не сохраняя",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "transaction",
+                            "batch"
+                        ],
+                        "type": "string",
+                        "default": "transaction",
+                        "description": "transaction - всё или ничего, batch - сохранить валидные строки",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ImportReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse413"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ImportReportResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/list": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение списка подписок (есть фильтрация по ID пользователя и по названию сервиса)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Netflix",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.SubscriptionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/read/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получить данные подписки по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Проверка готовности: доступность БД и применённые миграции",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restore/{id}": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Восстановить удалённую подписку по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Статистика трат пользователя: активные подписки, средние траты в месяц, самый дорогой сервис и т.д.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.UserStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/sum": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение суммы стоимости всех подписок за выбранный период по ID пользователя и имени сервиса",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Netflix",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "06-2025",
+                        "description": "Начало периода в формате MM-YYYY",
+                        "name": "period_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "08-2025",
+                        "description": "Конец периода в формате MM-YYYY",
+                        "name": "period_end",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.SumResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenant/settings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Настройки текущего тенанта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) или API-ключ",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта (только для администраторов платформы)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.TenantResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Изменение настроек текущего тенанта: название и валюта по умолчанию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID тенанта (только для администраторов платформы)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Настройки тенанта",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.TenantSettingsExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants/create": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создание тенанта (только для администраторов платформы)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью JWT_PLATFORM_ROLE без tenant_id",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора платформы (вместо JWT)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные тенанта; id генерируется, если не указан",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.TenantExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.TenantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/tenants/list": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список тенантов (только для администраторов платформы)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью JWT_PLATFORM_ROLE без tenant_id",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора платформы (вместо JWT)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.TenantResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/update/{id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Обновить подписку по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные подписки",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.UpdateSubscriptionExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse409"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/calendar-token": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Выпуск (или перевыпуск) секретного токена календаря пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.CalendarTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/calendar.ics": {
+            "get": {
+                "produces": [
+                    "text/calendar"
+                ],
+                "summary": "iCalendar-лента предстоящих списаний по активным подпискам пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "11111111-1111-1111-1111-111111111111",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Секретный токен календаря",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/create": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Регистрация вебхука (секрет возвращается один раз)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "URL, типы событий и (необязательно) секрет",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/swagger.WebhookExample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.WebhookCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/delete/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Отключение вебхука по ID (журнал доставок сохраняется)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Журнал доставок вебхуков, новые сначала",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "webhook_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус: pending, succeeded или failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Количество записей (до 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.WebhookDeliveryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/list": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список вебхуков тенанта (без секретов)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/swagger.WebhookResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/redeliver/{id}": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Повторная отправка доставки вебхука по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer-токен (JWT) с ролью admin",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен администратора (вместо JWT с ролью admin)",
+                        "name": "X-Admin-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "ID доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse403"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse404"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse429"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/swagger.ErrorResponse500"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "swagger.APIKeyExample": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-12-31T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "write"
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "11111111-1111-1111-1111-111111111111"
+                }
+            }
+        },
+        "swagger.APIKeyIssuedResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "type": "string",
+                    "example": "sak_q3Xk9aB1mZ0pLr7sT2vW4yN6cE8gJ5hK1dF3aQ9xU0o"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "created"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "sak_q3Xk9aB1"
+                }
+            }
+        },
+        "swagger.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:00:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-12-31T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2025-07-02T08:30:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "sak_q3Xk9aB1"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": "2025-07-03T10:00:00Z"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "write"
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "11111111-1111-1111-1111-111111111111"
+                }
+            }
+        },
+        "swagger.BudgetEvaluationResponse": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "$ref": "#/definitions/swagger.BudgetResponse"
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/swagger.BudgetMonthResponse"
+                    }
+                }
+            }
+        },
+        "swagger.BudgetExample": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1500
+                },
+                "category": {
+                    "type": "string",
+                    "example": ""
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "11111111-1111-1111-1111-111111111111"
+                },
+                "warning_threshold": {
+                    "type": "integer",
+                    "example": 80
+                }
+            }
+        },
+        "swagger.BudgetMonthResponse": {
+            "type": "object",
+            "properties": {
+                "forecast": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "month": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "projected": {
+                    "type": "integer",
+                    "example": 1450
+                },
+                "spend": {
+                    "type": "integer",
+                    "example": 1299
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "warning",
+                        "exceeded"
+                    ],
+                    "example": "warning"
+                }
+            }
+        },
+        "swagger.BudgetResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1500
+                },
+                "category": {
+                    "type": "string",
+                    "example": ""
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "11111111-1111-1111-1111-111111111111"
+                },
+                "warning_threshold": {
+                    "type": "integer",
+                    "example": 80
+                }
+            }
+        },
+        "swagger.BulkCreateExample": {
+            "type": "object",
+            "properties": {
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/swagger.SubscriptionExample"
+                    }
+                }
+            }
+        },
+        "swagger.BulkDeleteExample": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "swagger.BulkItemResultResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "end_date is before start_date"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "valid",
+                        "invalid",
+                        "created",
+                        "matched",
+                        "updated",
+                        "deleted",
+                        "not_found"
+                    ],
+                    "example": "created"
+                }
+            }
+        },
+        "swagger.BulkResultResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/swagger.BulkItemResultResponse"
+                    }
+                }
+            }
+        },
+        "swagger.BulkUpdateChangesExample": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 1099
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                }
+            }
+        },
+        "swagger.BulkUpdateExample": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "$ref": "#/definitions/swagger.BulkUpdateChangesExample"
+                },
+                "filter": {
+                    "$ref": "#/definitions/swagger.BulkUpdateFilterExample"
+                }
+            }
+        },
+        "swagger.BulkUpdateFilterExample": {
+            "type": "object",
+            "properties": {
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "11111111-1111-1111-1111-111111111111"
+                }
+            }
+        },
+        "swagger.CalendarTokenResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "3f7c2a9e..."
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/users/11111111-1111-1111-1111-111111111111/calendar.ics?token=3f7c2a9e..."
+                }
+            }
+        },
+        "swagger.CohortReportResponse": {
+            "type": "object",
+            "properties": {
+                "cohorts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/swagger.CohortResponse"
+                    }
+                },
+                "offsets": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        3,
+                        6,
+                        12
+                    ]
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                }
+            }
+        },
+        "swagger.CohortResponse": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "retention": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    },
+                    "example": [
+                        0.95,
+                        0.8,
+                        0.6
+                    ]
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 40
+                }
+            }
+        },
+        "swagger.DuplicateResponse": {
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "$ref": "#/definitions/swagger.SubscriptionResponse"
+                },
+                "original": {
+                    "$ref": "#/definitions/swagger.SubscriptionResponse"
+                },
+                "service": {
+                    "type": "string",
+                    "example": "netflix"
+                }
+            }
+        },
+        "swagger.ErrorResponse400": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid {id/request/json}"
+                }
+            }
+        },
+        "swagger.ErrorResponse401": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "authentication required"
+                }
+            }
+        },
+        "swagger.ErrorResponse403": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "admin access required"
+                }
+            }
+        },
+        "swagger.ErrorResponse404": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "record not found in db"
+                }
+            }
+        },
+        "swagger.ErrorResponse409": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "overlapping active subscription exists"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "swagger.ErrorResponse413": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "file is too large"
+                }
+            }
+        },
+        "swagger.ErrorResponse429": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "rate limit exceeded"
+                }
+            }
+        },
+        "swagger.ErrorResponse500": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "failed to {create/find/update/delete} record in db"
+                }
+            }
+        },
+        "swagger.ErrorResponse503": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "event stream unavailable"
+                }
+            }
+        },
+        "swagger.HealthCheckResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "context deadline exceeded"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 0.42
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "fail"
+                    ],
+                    "example": "ok"
+                }
+            }
+        },
+        "swagger.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/swagger.HealthCheckResponse"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "unavailable"
+                    ],
+                    "example": "ok"
+                }
+            }
+        },
+        "swagger.ImportReportResponse": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/swagger.ImportRowErrorResponse"
+                    }
+                },
+                "inserted": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "transaction"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "valid": {
+                    "type": "integer",
+                    "example": 999
+                }
+            }
+        },
+        "swagger.ImportRowErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid start_date"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "swagger.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "message": {
+                    "type": "string",
+                    "example": "{created/updated/deleted}"
+                }
+            }
+        },
+        "swagger.MonthMovementResponse": {
+            "type": "object",
+            "properties": {
+                "churned": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "month": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "new_subscriptions": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "swagger.MonthOverMonthResponse": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "integer",
+                    "example": 399
+                },
+                "change_percent": {
+                    "type": "number",
+                    "example": 39.94
+                },
+                "current_spend": {
+                    "type": "integer",
+                    "example": 1398
+                },
+                "previous_spend": {
+                    "type": "integer",
+                    "example": 999
+                }
+            }
+        },
+        "swagger.PriceAnomalyReportResponse": {
+            "type": "object",
+            "properties": {
+                "anomalies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/swagger.PriceAnomalyResponse"
+                    }
+                },
+                "factor": {
+                    "type": "number",
+                    "example": 5
+                },
+                "min_sample": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "swagger.PriceAnomalyResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "median": {
+                    "type": "number",
+                    "example": 999
+                },
+                "month": {
+                    "type": "string",
+                    "example": "08-2025"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 99900
+                },
+                "ratio": {
+                    "type": "number",
+                    "example": 100
+                },
+                "sample_size": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "11111111-1111-1111-1111-111111111111"
+                }
+            }
+        },
+        "swagger.ServiceMonthStatsResponse": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "revenue": {
+                    "type": "integer",
+                    "example": 41958
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "netflix"
+                },
+                "subscribers": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "swagger.ServicePriceResponse": {
+            "type": "object",
             "properties": {
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
-                "message": {
+                "months": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 999
+                },
+                "service_name": {
                     "type": "string",
-                    "example": "{created/updated/deleted}"
+                    "example": "Netflix"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "07-2025"
+                }
+            }
+        },
+        "swagger.ServicePriceStatsResponse": {
+            "type": "object",
+            "properties": {
+                "average_price": {
+                    "type": "number",
+                    "example": 949.5
+                },
+                "max_price": {
+                    "type": "integer",
+                    "example": 1299
+                },
+                "min_price": {
+                    "type": "integer",
+                    "example": 599
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "netflix"
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 42
                 }
             }
         },
         "swagger.SubscriptionExample": {
             "type": "object",
             "properties": {
+                "billing_cycle": {
+                    "type": "string",
+                    "enum": [
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "entertainment"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
                 "price": {
                     "type": "integer",
                     "example": 999
@@ -376,6 +3660,23 @@ const docTemplate = `{
         "swagger.SubscriptionResponse": {
             "type": "object",
             "properties": {
+                "billing_cycle": {
+                    "type": "string",
+                    "enum": [
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ],
+                    "example": "monthly"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "entertainment"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -401,12 +3702,67 @@ const docTemplate = `{
         "swagger.SumResponse": {
             "type": "object",
             "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "sum_price": {
                     "type": "integer",
                     "example": 999
                 }
             }
         },
+        "swagger.TenantExample": {
+            "type": "object",
+            "properties": {
+                "default_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "22222222-2222-2222-2222-222222222222"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                }
+            }
+        },
+        "swagger.TenantResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:00:00Z"
+                },
+                "default_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "22222222-2222-2222-2222-222222222222"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                }
+            }
+        },
+        "swagger.TenantSettingsExample": {
+            "type": "object",
+            "properties": {
+                "default_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                }
+            }
+        },
         "swagger.UpdateSubscriptionExample": {
             "type": "object",
             "properties": {
@@ -419,6 +3775,199 @@ const docTemplate = `{
                     "example": "Yandex"
                 }
             }
+        },
+        "swagger.UserStatsResponse": {
+            "type": "object",
+            "properties": {
+                "active_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "average_monthly_spend": {
+                    "type": "number",
+                    "example": 1198.5
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "lifetime_spend": {
+                    "type": "integer",
+                    "example": 4794
+                },
+                "longest_running": {
+                    "$ref": "#/definitions/swagger.ServicePriceResponse"
+                },
+                "month": {
+                    "type": "string",
+                    "example": "10-2025"
+                },
+                "month_over_month": {
+                    "$ref": "#/definitions/swagger.MonthOverMonthResponse"
+                },
+                "most_expensive": {
+                    "$ref": "#/definitions/swagger.ServicePriceResponse"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "11111111-1111-1111-1111-111111111111"
+                }
+            }
+        },
+        "swagger.WebhookCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "message": {
+                    "type": "string",
+                    "example": "created"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_3q2-7wR1x0yZb4TfK9mN2pLc8vA5sD6gH1jE0uI7oQk"
+                }
+            }
+        },
+        "swagger.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:00:00Z"
+                },
+                "delivered_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:05:00Z"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "6b0e1f5a-3c2d-4e8f-9a7b-1c2d3e4f5a6b"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "subscription.created"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_attempt_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:00:01Z"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "unexpected status 503"
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:01:01Z"
+                },
+                "payload": {
+                    "$ref": "#/definitions/swagger.WebhookEventResponse"
+                },
+                "redelivery_of": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "response_status": {
+                    "type": "integer",
+                    "example": 503
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:00:01Z"
+                },
+                "webhook_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "swagger.WebhookEventResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/swagger.SubscriptionResponse"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6b0e1f5a-3c2d-4e8f-9a7b-1c2d3e4f5a6b"
+                },
+                "occurred_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:00:00Z"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "subscription.created"
+                }
+            }
+        },
+        "swagger.WebhookExample": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscription.created",
+                        "subscription.deleted"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "my-webhook-signing-secret"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/subscriptions"
+                }
+            }
+        },
+        "swagger.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-07-01T12:00:00Z"
+                },
+                "disabled_at": {
+                    "type": "string",
+                    "example": "2025-07-03T10:00:00Z"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscription.created",
+                        "subscription.deleted"
+                    ]
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/subscriptions"
+                }
+            }
         }
     }
 }`
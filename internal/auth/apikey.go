@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	apiKeyPrefix       = "sak_"
+	apiKeyDisplayChars = 12
+)
+
+// GenerateAPIKey returns a new random key and the short prefix shown in
+// listings so operators can tell keys apart without seeing the secret.
+func GenerateAPIKey() (key, prefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:apiKeyDisplayChars], nil
+}
+
+// HashAPIKey is what gets stored and looked up. Keys carry 256 bits of
+// entropy, so a plain SHA-256 is enough and keeps lookups cheap.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadJWKS(t *testing.T) {
+	strong := generateKey(t, 2048)
+	weak := generateKey(t, 1024)
+
+	tests := []struct {
+		name    string
+		keys    map[string]*rsa.PrivateKey
+		wantErr string
+	}{
+		{"2048-bit key", map[string]*rsa.PrivateKey{"k1": strong}, ""},
+		{"1024-bit key", map[string]*rsa.PrivateKey{"weak": weak}, "modulus shorter than 2048 bits"},
+		{"weak key next to a strong one", map[string]*rsa.PrivateKey{"k1": strong, "weak": weak}, "modulus shorter than 2048 bits"},
+		{"no keys", map[string]*rsa.PrivateKey{}, "no RS256 signing keys"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := LoadJWKS(writeJWKS(t, tt.keys))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadJWKS() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadJWKS() error = %v", err)
+			}
+			if len(keys) != len(tt.keys) {
+				t.Fatalf("LoadJWKS() returned %d keys, want %d", len(keys), len(tt.keys))
+			}
+		})
+	}
+}
+
+func TestLoadJWKSSkipsOtherKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	raw := `{"keys":[
+		{"kty":"EC","kid":"ec","crv":"P-256","x":"AA","y":"AA"},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"AA","e":"AQAB"},
+		{"kty":"RSA","kid":"ps","alg":"PS256","n":"AA","e":"AQAB"}
+	]}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadJWKS(path); err == nil || !strings.Contains(err.Error(), "no RS256 signing keys") {
+		t.Fatalf("LoadJWKS() error = %v, want no RS256 signing keys", err)
+	}
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"subscription-aggregator/internal/config"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrExpiredToken     = errors.New("token is expired")
+	ErrInvalidClaims    = errors.New("invalid token claims")
+)
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Role      string   `json:"role"`
+	Roles     []string `json:"roles"`
+	TenantID  string   `json:"tenant_id"`
+}
+
+// audience accepts both forms allowed by RFC 7519: a string or an array.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Verifier validates bearer JWTs signed with HS256 (shared secret) or RS256
+// (keys from a local JWKS file) and turns them into a Principal.
+type Verifier struct {
+	secret       []byte
+	keys         map[string]*rsa.PublicKey
+	issuer       string
+	audience     string
+	adminRole    string
+	platformRole string
+	leeway       time.Duration
+	now          func() time.Time
+}
+
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	v := &Verifier{
+		secret:       []byte(cfg.JWTSecret),
+		issuer:       cfg.JWTIssuer,
+		audience:     cfg.JWTAudience,
+		adminRole:    cfg.AdminRole,
+		platformRole: cfg.PlatformRole,
+		leeway:       cfg.JWTLeeway,
+		now:          time.Now,
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+	}
+	return v, nil
+}
+
+// Enabled reports whether any signing key is configured.
+func (v *Verifier) Enabled() bool {
+	return len(v.secret) > 0 || len(v.keys) > 0
+}
+
+func (v *Verifier) Verify(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, ErrMalformedToken
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Principal{}, ErrMalformedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, ErrMalformedToken
+	}
+	if err := v.verifySignature(h, parts[0]+"."+parts[1], signature); err != nil {
+		return Principal{}, err
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return Principal{}, ErrMalformedToken
+	}
+	return v.principal(c)
+}
+
+func (v *Verifier) verifySignature(h header, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch h.Alg {
+	case "HS256":
+		if len(v.secret) == 0 {
+			return ErrUnsupportedAlg
+		}
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case "RS256":
+		key, err := v.rsaKey(h.Kid)
+		if err != nil {
+			return err
+		}
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	default:
+		return ErrUnsupportedAlg
+	}
+}
+
+func (v *Verifier) rsaKey(kid string) (*rsa.PublicKey, error) {
+	if len(v.keys) == 0 {
+		return nil, ErrUnsupportedAlg
+	}
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	// A token without kid is only unambiguous when there is a single key.
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidSignature, kid)
+}
+
+func (v *Verifier) principal(c claims) (Principal, error) {
+	now := v.now()
+
+	if c.ExpiresAt == nil {
+		return Principal{}, fmt.Errorf("%w: exp is required", ErrInvalidClaims)
+	}
+	if now.After(time.Unix(*c.ExpiresAt, 0).Add(v.leeway)) {
+		return Principal{}, ErrExpiredToken
+	}
+	if c.NotBefore != nil && now.Add(v.leeway).Before(time.Unix(*c.NotBefore, 0)) {
+		return Principal{}, fmt.Errorf("%w: token is not valid yet", ErrInvalidClaims)
+	}
+	if v.issuer != "" && c.Issuer != v.issuer {
+		return Principal{}, fmt.Errorf("%w: unexpected issuer", ErrInvalidClaims)
+	}
+	if v.audience != "" && !contains(c.Audience, v.audience) {
+		return Principal{}, fmt.Errorf("%w: unexpected audience", ErrInvalidClaims)
+	}
+
+	userID, err := uuid.Parse(c.Subject)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: sub must be a user UUID", ErrInvalidClaims)
+	}
+
+	// Platform administration needs its own role: an admin token without a
+	// tenant_id claim administers only the default tenant.
+	platform := v.hasRole(c, v.platformRole)
+	principal := Principal{
+		UserID:        userID,
+		Admin:         platform || v.hasRole(c, v.adminRole),
+		PlatformAdmin: platform,
+		Method:        MethodJWT,
+	}
+	if c.TenantID != "" {
+		principal.TenantID, err = uuid.Parse(c.TenantID)
+		if err != nil {
+			return Principal{}, fmt.Errorf("%w: tenant_id must be a UUID", ErrInvalidClaims)
+		}
+		principal.TenantBound = true
+	}
+	return principal, nil
+}
+
+func (v *Verifier) hasRole(c claims, role string) bool {
+	return role != "" && (c.Role == role || contains(c.Roles, role))
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
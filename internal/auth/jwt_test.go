@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"subscription-aggregator/internal/config"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+	testNow    = time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	testUserID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+)
+
+func generateKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// writeJWKS writes the public halves of keys, keyed by kid, to a JWKS file.
+func writeJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) string {
+	t.Helper()
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	raw, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sign builds a compact JWS. key is a []byte secret for HS256 or an
+// *rsa.PrivateKey for RS256; any other alg gets a junk signature.
+func sign(t *testing.T, h header, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	input := encode(h) + "." + encode(claims)
+
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		signature = []byte("junk")
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub": testUserID.String(),
+		"exp": testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func with(claims map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, v := range claims {
+		out[k] = v
+	}
+	if value == nil {
+		delete(out, key)
+	} else {
+		out[key] = value
+	}
+	return out
+}
+
+func newTestVerifier(t *testing.T, cfg config.AuthConfig) *Verifier {
+	t.Helper()
+	if cfg.AdminRole == "" {
+		cfg.AdminRole = "admin"
+	}
+	v, err := NewVerifier(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func TestVerifyAlgorithms(t *testing.T) {
+	key1 := generateKey(t, 2048)
+	key2 := generateKey(t, 2048)
+	twoKeys := writeJWKS(t, map[string]*rsa.PrivateKey{"k1": key1, "k2": key2})
+	oneKey := writeJWKS(t, map[string]*rsa.PrivateKey{"only": key1})
+
+	hsOnly := newTestVerifier(t, config.AuthConfig{JWTSecret: string(testSecret)})
+	rsOnly := newTestVerifier(t, config.AuthConfig{JWKSFile: twoKeys})
+	rsSingle := newTestVerifier(t, config.AuthConfig{JWKSFile: oneKey})
+
+	claims := validClaims()
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		token    string
+		wantErr  error
+	}{
+		{"HS256", hsOnly, sign(t, header{Alg: "HS256"}, claims, testSecret), nil},
+		{"HS256 wrong secret", hsOnly, sign(t, header{Alg: "HS256"}, claims, []byte("another secret of 32 bytes......")), ErrInvalidSignature},
+		{"HS256 without secret configured", rsOnly, sign(t, header{Alg: "HS256"}, claims, []byte{}), ErrUnsupportedAlg},
+		{"RS256 without keys configured", hsOnly, sign(t, header{Alg: "RS256", Kid: "k1"}, claims, key1), ErrUnsupportedAlg},
+		{"alg none", hsOnly, sign(t, header{Alg: "none"}, claims, nil), ErrUnsupportedAlg},
+		{"alg HS512", hsOnly, sign(t, header{Alg: "HS512"}, claims, nil), ErrUnsupportedAlg},
+		{"RS256 kid k1", rsOnly, sign(t, header{Alg: "RS256", Kid: "k1"}, claims, key1), nil},
+		{"RS256 kid k2", rsOnly, sign(t, header{Alg: "RS256", Kid: "k2"}, claims, key2), nil},
+		{"RS256 kid of another key", rsOnly, sign(t, header{Alg: "RS256", Kid: "k1"}, claims, key2), ErrInvalidSignature},
+		{"RS256 unknown kid", rsOnly, sign(t, header{Alg: "RS256", Kid: "k3"}, claims, key1), ErrInvalidSignature},
+		{"RS256 no kid with several keys", rsOnly, sign(t, header{Alg: "RS256"}, claims, key1), ErrInvalidSignature},
+		{"RS256 no kid with a single key", rsSingle, sign(t, header{Alg: "RS256"}, claims, key1), nil},
+		{"two segments", hsOnly, "a.b", ErrMalformedToken},
+		{"header not base64", hsOnly, "!!." + strings.SplitN(sign(t, header{Alg: "HS256"}, claims, testSecret), ".", 2)[1], ErrMalformedToken},
+		{"signature not base64", hsOnly, sign(t, header{Alg: "HS256"}, claims, testSecret) + "!", ErrMalformedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := tt.verifier.Verify(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && principal.UserID != testUserID {
+				t.Fatalf("UserID = %s, want %s", principal.UserID, testUserID)
+			}
+		})
+	}
+}
+
+func TestVerifyTamperedPayload(t *testing.T) {
+	v := newTestVerifier(t, config.AuthConfig{JWTSecret: string(testSecret)})
+	parts := strings.Split(sign(t, header{Alg: "HS256"}, validClaims(), testSecret), ".")
+
+	forged, _ := json.Marshal(with(validClaims(), "role", "admin"))
+	parts[1] = base64.RawURLEncoding.EncodeToString(forged)
+
+	if _, err := v.Verify(strings.Join(parts, ".")); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	tenantID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	cfg := config.AuthConfig{
+		JWTSecret:    string(testSecret),
+		JWTIssuer:    "https://issuer.example",
+		JWTAudience:  "aggregator",
+		JWTLeeway:    30 * time.Second,
+		PlatformRole: "platform-admin",
+	}
+	base := with(with(validClaims(), "iss", cfg.JWTIssuer), "aud", cfg.JWTAudience)
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr error
+		want    Principal
+	}{
+		{"valid", base, nil, Principal{UserID: testUserID, Method: MethodJWT}},
+		{"missing exp", with(base, "exp", nil), ErrInvalidClaims, Principal{}},
+		{"expired", with(base, "exp", testNow.Add(-time.Minute).Unix()), ErrExpiredToken, Principal{}},
+		{"expired within leeway", with(base, "exp", testNow.Add(-10*time.Second).Unix()), nil, Principal{UserID: testUserID, Method: MethodJWT}},
+		{"not valid yet", with(base, "nbf", testNow.Add(time.Minute).Unix()), ErrInvalidClaims, Principal{}},
+		{"nbf within leeway", with(base, "nbf", testNow.Add(10*time.Second).Unix()), nil, Principal{UserID: testUserID, Method: MethodJWT}},
+		{"wrong issuer", with(base, "iss", "https://evil.example"), ErrInvalidClaims, Principal{}},
+		{"missing issuer", with(base, "iss", nil), ErrInvalidClaims, Principal{}},
+		{"audience in array", with(base, "aud", []string{"other", "aggregator"}), nil, Principal{UserID: testUserID, Method: MethodJWT}},
+		{"wrong audience", with(base, "aud", "other"), ErrInvalidClaims, Principal{}},
+		{"missing audience", with(base, "aud", nil), ErrInvalidClaims, Principal{}},
+		{"sub not a UUID", with(base, "sub", "alice"), ErrInvalidClaims, Principal{}},
+		{"admin role", with(base, "role", "admin"), nil, Principal{UserID: testUserID, Admin: true, Method: MethodJWT}},
+		{"admin in roles", with(base, "roles", []string{"viewer", "admin"}), nil, Principal{UserID: testUserID, Admin: true, Method: MethodJWT}},
+		{"other role", with(base, "role", "viewer"), nil, Principal{UserID: testUserID, Method: MethodJWT}},
+		{"admin without tenant is not a platform admin", with(base, "role", "admin"), nil, Principal{UserID: testUserID, Admin: true, Method: MethodJWT}},
+		{"platform role", with(base, "role", "platform-admin"), nil, Principal{UserID: testUserID, Admin: true, PlatformAdmin: true, Method: MethodJWT}},
+		{"platform in roles", with(base, "roles", []string{"admin", "platform-admin"}), nil, Principal{UserID: testUserID, Admin: true, PlatformAdmin: true, Method: MethodJWT}},
+		{"tenant", with(base, "tenant_id", tenantID.String()), nil, Principal{UserID: testUserID, Method: MethodJWT, TenantID: tenantID, TenantBound: true}},
+		{"platform role with tenant", with(with(base, "role", "platform-admin"), "tenant_id", tenantID.String()), nil, Principal{UserID: testUserID, Admin: true, PlatformAdmin: true, Method: MethodJWT, TenantID: tenantID, TenantBound: true}},
+		{"tenant not a UUID", with(base, "tenant_id", "acme"), ErrInvalidClaims, Principal{}},
+	}
+	v := newTestVerifier(t, cfg)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := v.Verify(sign(t, header{Alg: "HS256"}, tt.claims, testSecret))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if principal.UserID != tt.want.UserID || principal.Admin != tt.want.Admin || principal.PlatformAdmin != tt.want.PlatformAdmin ||
+				principal.Method != tt.want.Method || principal.TenantID != tt.want.TenantID || principal.TenantBound != tt.want.TenantBound {
+				t.Fatalf("Verify() = %+v, want %+v", principal, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MethodJWT        = "jwt"
+	MethodAPIKey     = "api_key"
+	MethodAdminToken = "admin_token"
+)
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var AllScopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+// Principal is the authenticated caller. Normal users may only touch rows
+// whose user_id equals UserID; admins may touch any row of their tenant. API
+// keys also carry scopes, and a key that is not bound to a user acts for every
+// user. TenantBound is set when the credential itself names the tenant.
+// PlatformAdmin is granted only by the platform role or the static admin
+// token; such callers may pick a tenant per request unless their credential
+// is tenant bound.
+type Principal struct {
+	UserID        uuid.UUID
+	Admin         bool
+	PlatformAdmin bool
+	Method        string
+	KeyID         uint
+	Scopes        []string
+	TenantID      uuid.UUID
+	TenantBound   bool
+}
+
+// Platform reports whether the caller administers the whole installation
+// rather than a single tenant.
+func (p Principal) Platform() bool {
+	return p.PlatformAdmin && !p.TenantBound
+}
+
+// Unrestricted reports whether the caller may access every user's data. An
+// API key bound to a user stays confined to that user, even with the admin
+// scope.
+func (p Principal) Unrestricted() bool {
+	if p.Method == MethodAPIKey {
+		return p.UserID == uuid.Nil
+	}
+	return p.Admin
+}
+
+func (p Principal) CanAccess(userID uuid.UUID) bool {
+	return p.Unrestricted() || p.UserID == userID
+}
+
+// HasScope reports whether the caller may perform operations of the given
+// scope. Only API keys are limited by scopes; the admin scope implies the rest.
+func (p Principal) HasScope(scope string) bool {
+	if p.Method != MethodAPIKey {
+		return scope != ScopeAdmin || p.Admin
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
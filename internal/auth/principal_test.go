@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPrincipalCanAccess(t *testing.T) {
+	self := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	other := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+
+	tests := []struct {
+		name      string
+		principal Principal
+		userID    uuid.UUID
+		want      bool
+	}{
+		{"user reads own data", Principal{UserID: self, Method: MethodJWT}, self, true},
+		{"user reads other data", Principal{UserID: self, Method: MethodJWT}, other, false},
+		{"admin reads other data", Principal{UserID: self, Admin: true, Method: MethodJWT}, other, true},
+		{"admin token", Principal{Admin: true, Method: MethodAdminToken}, other, true},
+		{"user-bound API key reads own data", Principal{UserID: self, Method: MethodAPIKey}, self, true},
+		{"user-bound API key reads other data", Principal{UserID: self, Method: MethodAPIKey}, other, false},
+		{"unbound API key", Principal{Method: MethodAPIKey}, other, true},
+		{"user-bound admin API key reads own data", Principal{UserID: self, Admin: true, Method: MethodAPIKey, Scopes: []string{ScopeAdmin}}, self, true},
+		{"user-bound admin API key reads other data", Principal{UserID: self, Admin: true, Method: MethodAPIKey, Scopes: []string{ScopeAdmin}}, other, false},
+		{"unbound admin API key", Principal{Admin: true, Method: MethodAPIKey, Scopes: []string{ScopeAdmin}}, other, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.CanAccess(tt.userID); got != tt.want {
+				t.Fatalf("CanAccess() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrincipalHasScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		scope     string
+		want      bool
+	}{
+		{"user read", Principal{Method: MethodJWT}, ScopeRead, true},
+		{"user write", Principal{Method: MethodJWT}, ScopeWrite, true},
+		{"user admin", Principal{Method: MethodJWT}, ScopeAdmin, false},
+		{"admin JWT admin", Principal{Admin: true, Method: MethodJWT}, ScopeAdmin, true},
+		{"admin token admin", Principal{Admin: true, Method: MethodAdminToken}, ScopeAdmin, true},
+		{"read key read", Principal{Method: MethodAPIKey, Scopes: []string{ScopeRead}}, ScopeRead, true},
+		{"read key write", Principal{Method: MethodAPIKey, Scopes: []string{ScopeRead}}, ScopeWrite, false},
+		{"write key read", Principal{Method: MethodAPIKey, Scopes: []string{ScopeWrite}}, ScopeRead, false},
+		{"admin key write", Principal{Method: MethodAPIKey, Scopes: []string{ScopeAdmin}}, ScopeWrite, true},
+		{"key without scopes", Principal{Method: MethodAPIKey}, ScopeRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.HasScope(tt.scope); got != tt.want {
+				t.Fatalf("HasScope(%q) = %v, want %v", tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrincipalPlatform(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		want      bool
+	}{
+		{"user", Principal{Method: MethodJWT}, false},
+		{"admin without tenant", Principal{Admin: true, Method: MethodJWT}, false},
+		{"platform admin", Principal{Admin: true, PlatformAdmin: true, Method: MethodJWT}, true},
+		{"platform admin bound to a tenant", Principal{Admin: true, PlatformAdmin: true, Method: MethodJWT, TenantBound: true}, false},
+		{"admin token", Principal{Admin: true, PlatformAdmin: true, Method: MethodAdminToken}, true},
+		{"admin API key", Principal{Method: MethodAPIKey, Scopes: []string{ScopeAdmin}, TenantBound: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.Platform(); got != tt.want {
+				t.Fatalf("Platform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,662 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "***"
+
+type Config struct {
+	HTTP      HTTPConfig      `yaml:"http"`
+	DB        DBConfig        `yaml:"db"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Tenancy   TenancyConfig   `yaml:"tenancy"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks"`
+	Outbox    OutboxConfig    `yaml:"outbox"`
+	Events    EventsConfig    `yaml:"events"`
+	Features  FeaturesConfig  `yaml:"features"`
+}
+
+type HTTPConfig struct {
+	Port              int           `yaml:"port"                env:"PORT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"        env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"       env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"        env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"    env:"SHUTDOWN_TIMEOUT"`
+	TrustedProxies    []string      `yaml:"trusted_proxies"     env:"HTTP_TRUSTED_PROXIES"`
+}
+
+type DBConfig struct {
+	URL             string        `yaml:"url"               env:"DATABASE_URL"`
+	Host            string        `yaml:"host"              env:"DB_HOST"`
+	Port            int           `yaml:"port"              env:"DB_PORT"`
+	User            string        `yaml:"user"              env:"DB_USER"`
+	Password        string        `yaml:"password"          env:"DB_PASS"`
+	Name            string        `yaml:"name"              env:"DB_NAME"`
+	SSLMode         string        `yaml:"sslmode"           env:"DB_SSLMODE"`
+	MaxOpenConns    int           `yaml:"max_open_conns"    env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"    env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnectRetries  int           `yaml:"connect_retries"   env:"DB_CONNECT_RETRIES"`
+	RetryInterval   time.Duration `yaml:"retry_interval"    env:"DB_RETRY_INTERVAL"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter"     env:"OTEL_TRACES_EXPORTER"`
+	Endpoint    string `yaml:"endpoint"     env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Headers     string `yaml:"headers"      env:"OTEL_EXPORTER_OTLP_HEADERS"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+// MetricsConfig sets up the Prometheus endpoint, which listens on its own port
+// rather than next to the API.
+type MetricsConfig struct {
+	Port            int           `yaml:"port"             env:"METRICS_PORT"`
+	RefreshInterval time.Duration `yaml:"refresh_interval" env:"METRICS_REFRESH_INTERVAL"`
+}
+
+type AuthConfig struct {
+	JWTSecret    string        `yaml:"jwt_secret"    env:"JWT_SECRET"`
+	JWKSFile     string        `yaml:"jwks_file"     env:"JWT_JWKS_FILE"`
+	JWTIssuer    string        `yaml:"jwt_issuer"    env:"JWT_ISSUER"`
+	JWTAudience  string        `yaml:"jwt_audience"  env:"JWT_AUDIENCE"`
+	JWTLeeway    time.Duration `yaml:"jwt_leeway"    env:"JWT_LEEWAY"`
+	AdminRole    string        `yaml:"admin_role"    env:"JWT_ADMIN_ROLE"`
+	PlatformRole string        `yaml:"platform_role" env:"JWT_PLATFORM_ROLE"`
+	AdminToken   string        `yaml:"admin_token"   env:"ADMIN_TOKEN"`
+}
+
+// TenancyConfig names the tenant that credentials without a tenant (the admin
+// token and JWTs lacking a tenant_id claim) act in.
+type TenancyConfig struct {
+	DefaultTenantID string `yaml:"default_tenant_id" env:"DEFAULT_TENANT_ID"`
+}
+
+// RateLimitConfig holds the token-bucket limits of each route group. Every
+// client (API key, user or IP address) gets its own bucket per group. IP
+// applies per client IP to every request before authentication.
+type RateLimitConfig struct {
+	Enabled bool      `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	IP      RateLimit `yaml:"ip"      env:"RATE_LIMIT_IP"`
+	Default RateLimit `yaml:"default" env:"RATE_LIMIT_DEFAULT"`
+	Reports RateLimit `yaml:"reports" env:"RATE_LIMIT_REPORTS"`
+	Bulk    RateLimit `yaml:"bulk"    env:"RATE_LIMIT_BULK"`
+}
+
+// RateLimit is written as "<requests>/<period>[,burst=<n>]", e.g. "60/1m" or
+// "10/1s,burst=50": the bucket refills at requests per period and holds up to
+// burst tokens (requests by default). "off" disables the limit.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (r *RateLimit) UnmarshalText(text []byte) error {
+	spec := strings.TrimSpace(string(text))
+	if spec == "" || spec == "off" {
+		*r = RateLimit{}
+		return nil
+	}
+
+	rate, options, _ := strings.Cut(spec, ",")
+	requests, period, ok := strings.Cut(rate, "/")
+	if !ok {
+		return fmt.Errorf("rate limit %q must look like <requests>/<period>", spec)
+	}
+
+	var limit RateLimit
+	var err error
+	if limit.Requests, err = strconv.Atoi(strings.TrimSpace(requests)); err != nil || limit.Requests <= 0 {
+		return fmt.Errorf("rate limit %q: requests must be a positive integer", spec)
+	}
+	period = strings.TrimSpace(period)
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	if limit.Period, err = time.ParseDuration(period); err != nil || limit.Period <= 0 {
+		return fmt.Errorf("rate limit %q: period must be a positive duration", spec)
+	}
+
+	limit.Burst = limit.Requests
+	if options != "" {
+		key, value, _ := strings.Cut(strings.TrimSpace(options), "=")
+		if key != "burst" {
+			return fmt.Errorf("rate limit %q: unknown option %q", spec, key)
+		}
+		if limit.Burst, err = strconv.Atoi(value); err != nil || limit.Burst <= 0 {
+			return fmt.Errorf("rate limit %q: burst must be a positive integer", spec)
+		}
+	}
+
+	*r = limit
+	return nil
+}
+
+func (r RateLimit) String() string {
+	if r.Requests == 0 {
+		return "off"
+	}
+	// time.Duration prints a minute as "1m0s"; drop the zero units.
+	period := r.Period.String()
+	if strings.HasSuffix(period, "m0s") {
+		period = strings.TrimSuffix(period, "0s")
+	}
+	if strings.HasSuffix(period, "h0m") {
+		period = strings.TrimSuffix(period, "0m")
+	}
+
+	if r.Burst == r.Requests {
+		return fmt.Sprintf("%d/%s", r.Requests, period)
+	}
+	return fmt.Sprintf("%d/%s,burst=%d", r.Requests, period, r.Burst)
+}
+
+// WebhooksConfig controls the delivery worker. Enabled only decides whether
+// this process sends deliveries; endpoints can be managed either way.
+// AllowPrivateTargets lifts the public address check for development and
+// installations whose receivers live on an internal network.
+type WebhooksConfig struct {
+	Enabled             bool          `yaml:"enabled"               env:"WEBHOOKS_ENABLED"`
+	PollInterval        time.Duration `yaml:"poll_interval"         env:"WEBHOOK_POLL_INTERVAL"`
+	Timeout             time.Duration `yaml:"timeout"               env:"WEBHOOK_TIMEOUT"`
+	MaxAttempts         int           `yaml:"max_attempts"          env:"WEBHOOK_MAX_ATTEMPTS"`
+	BackoffBase         time.Duration `yaml:"backoff_base"          env:"WEBHOOK_BACKOFF_BASE"`
+	BackoffMax          time.Duration `yaml:"backoff_max"           env:"WEBHOOK_BACKOFF_MAX"`
+	RenewalLead         time.Duration `yaml:"renewal_lead"          env:"WEBHOOK_RENEWAL_LEAD"`
+	AllowPrivateTargets bool          `yaml:"allow_private_targets" env:"WEBHOOK_ALLOW_PRIVATE_TARGETS"`
+}
+
+// OutboxConfig controls the relay that publishes events saved alongside
+// subscription changes. Publishers lists where every event goes: webhooks,
+// log, http, nats and kafka. Events that no relay publishes are kept for
+// UnpublishedRetention.
+type OutboxConfig struct {
+	Enabled              bool          `yaml:"enabled"               env:"OUTBOX_ENABLED"`
+	Publishers           []string      `yaml:"publishers"            env:"OUTBOX_PUBLISHERS"`
+	HTTPURL              string        `yaml:"http_url"              env:"OUTBOX_HTTP_URL"`
+	HTTPTimeout          time.Duration `yaml:"http_timeout"          env:"OUTBOX_HTTP_TIMEOUT"`
+	NATSURL              string        `yaml:"nats_url"              env:"OUTBOX_NATS_URL"`
+	NATSSubject          string        `yaml:"nats_subject"          env:"OUTBOX_NATS_SUBJECT"`
+	KafkaBrokers         []string      `yaml:"kafka_brokers"         env:"OUTBOX_KAFKA_BROKERS"`
+	KafkaTopic           string        `yaml:"kafka_topic"           env:"OUTBOX_KAFKA_TOPIC"`
+	PollInterval         time.Duration `yaml:"poll_interval"         env:"OUTBOX_POLL_INTERVAL"`
+	Retention            time.Duration `yaml:"retention"             env:"OUTBOX_RETENTION"`
+	UnpublishedRetention time.Duration `yaml:"unpublished_retention" env:"OUTBOX_UNPUBLISHED_RETENTION"`
+}
+
+// EventsConfig tunes the /events stream. BufferSize recent events are kept
+// in memory for clients resuming with Last-Event-ID; older ones are read back
+// from the outbox.
+type EventsConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"EVENTS_POLL_INTERVAL"`
+	Heartbeat    time.Duration `yaml:"heartbeat"     env:"EVENTS_HEARTBEAT"`
+	BufferSize   int           `yaml:"buffer_size"   env:"EVENTS_BUFFER_SIZE"`
+}
+
+type FeaturesConfig struct {
+	StrictDuplicates bool `yaml:"strict_duplicates" env:"STRICT_DUPLICATES"`
+}
+
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Port:              8080,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		DB: DBConfig{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnectRetries:  10,
+			RetryInterval:   2 * time.Second,
+		},
+		Log: LogConfig{Level: "info"},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "subscription-aggregator",
+		},
+		Metrics: MetricsConfig{
+			Port:            9090,
+			RefreshInterval: 30 * time.Second,
+		},
+		Auth: AuthConfig{
+			JWTLeeway: 30 * time.Second,
+			AdminRole: "admin",
+		},
+		Tenancy: TenancyConfig{DefaultTenantID: "00000000-0000-0000-0000-000000000001"},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			IP:      RateLimit{Requests: 1200, Period: time.Minute, Burst: 200},
+			Default: RateLimit{Requests: 600, Period: time.Minute, Burst: 100},
+			Reports: RateLimit{Requests: 60, Period: time.Minute, Burst: 10},
+			Bulk:    RateLimit{Requests: 10, Period: time.Minute, Burst: 2},
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:      true,
+			PollInterval: 5 * time.Second,
+			Timeout:      10 * time.Second,
+			MaxAttempts:  8,
+			BackoffBase:  30 * time.Second,
+			BackoffMax:   6 * time.Hour,
+			RenewalLead:  72 * time.Hour,
+		},
+		Outbox: OutboxConfig{
+			Enabled:              true,
+			Publishers:           []string{"webhooks"},
+			HTTPTimeout:          10 * time.Second,
+			NATSSubject:          "aggregator.events",
+			KafkaTopic:           "subscription-events",
+			PollInterval:         time.Second,
+			Retention:            7 * 24 * time.Hour,
+			UnpublishedRetention: 30 * 24 * time.Hour,
+		},
+		Events: EventsConfig{
+			PollInterval: time.Second,
+			Heartbeat:    15 * time.Second,
+			BufferSize:   1000,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, then the optional YAML file
+// named by CONFIG_FILE, then the environment (including an optional .env file),
+// each layer overriding the previous one.
+func Load() (Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, fmt.Errorf("load .env: %w", err)
+	}
+
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("read config file: %w", err)
+		}
+		decoder := yaml.NewDecoder(strings.NewReader(string(raw)))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&cfg); err != nil {
+			return Config{}, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.HTTP.Port > 0 && c.HTTP.Port < 65536, "http.port must be between 1 and 65535, got %d", c.HTTP.Port)
+	check(c.HTTP.ReadHeaderTimeout > 0, "http.read_header_timeout must be positive")
+	check(c.HTTP.ReadTimeout > 0, "http.read_timeout must be positive")
+	check(c.HTTP.WriteTimeout > 0, "http.write_timeout must be positive")
+	check(c.HTTP.IdleTimeout > 0, "http.idle_timeout must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout must be positive")
+	for _, proxy := range c.HTTP.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "http.trusted_proxies: %q is neither an IP address nor a CIDR range", proxy)
+	}
+
+	if c.DB.URL != "" {
+		u, err := url.Parse(c.DB.URL)
+		check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"),
+			"db.url must be a postgres:// or postgresql:// URL")
+	} else {
+		check(c.DB.Host != "", "db.host is required when db.url is not set")
+		check(c.DB.User != "", "db.user is required when db.url is not set")
+		check(c.DB.Name != "", "db.name is required when db.url is not set")
+		check(c.DB.Port > 0 && c.DB.Port < 65536, "db.port must be between 1 and 65535, got %d", c.DB.Port)
+	}
+	check(oneOf(c.DB.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+		"db.sslmode %q is not supported", c.DB.SSLMode)
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns must not be negative")
+	check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+		"db.max_idle_conns must not exceed db.max_open_conns")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must not be negative")
+	check(c.DB.ConnectRetries > 0, "db.connect_retries must be positive")
+	check(c.DB.RetryInterval > 0, "db.retry_interval must be positive")
+
+	check(oneOf(strings.ToLower(c.Log.Level), "debug", "info", "warn", "error"),
+		"log.level %q is not supported", c.Log.Level)
+
+	check(oneOf(strings.ToLower(c.Tracing.Exporter), "none", "stdout", "console", "otlp"),
+		"tracing.exporter %q is not supported", c.Tracing.Exporter)
+	if strings.EqualFold(c.Tracing.Exporter, "otlp") {
+		_, err := url.ParseRequestURI(c.Tracing.Endpoint)
+		check(err == nil, "tracing.endpoint must be a valid URL")
+	}
+
+	check(c.Metrics.Port > 0 && c.Metrics.Port < 65536, "metrics.port must be between 1 and 65535, got %d", c.Metrics.Port)
+	check(c.Metrics.Port != c.HTTP.Port, "metrics.port must differ from http.port")
+	check(c.Metrics.RefreshInterval > 0, "metrics.refresh_interval must be positive")
+
+	check(c.Auth.JWTSecret == "" || len(c.Auth.JWTSecret) >= 32, "auth.jwt_secret must be at least 32 bytes")
+	check(c.Auth.JWTLeeway >= 0, "auth.jwt_leeway must not be negative")
+	check(c.Auth.AdminRole != "", "auth.admin_role must not be empty")
+	check(c.Auth.PlatformRole != c.Auth.AdminRole, "auth.platform_role must differ from auth.admin_role")
+
+	check(c.Webhooks.PollInterval > 0, "webhooks.poll_interval must be positive")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts must be positive")
+	check(c.Webhooks.BackoffBase > 0, "webhooks.backoff_base must be positive")
+	check(c.Webhooks.BackoffMax >= c.Webhooks.BackoffBase, "webhooks.backoff_max must not be less than webhooks.backoff_base")
+	check(c.Webhooks.RenewalLead > 0, "webhooks.renewal_lead must be positive")
+
+	check(len(c.Outbox.Publishers) > 0, "outbox.publishers must not be empty")
+	for _, name := range c.Outbox.Publishers {
+		check(oneOf(name, "webhooks", "log", "http", "nats", "kafka"), "outbox.publishers: unknown publisher %q", name)
+		switch name {
+		case "http":
+			u, err := url.Parse(c.Outbox.HTTPURL)
+			check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "outbox.http_url must be an http or https URL when the http publisher is used")
+		case "nats":
+			check(c.Outbox.NATSURL != "", "outbox.nats_url must be set when the nats publisher is used")
+			check(c.Outbox.NATSSubject != "" && !strings.ContainsAny(c.Outbox.NATSSubject, " *>"), "outbox.nats_subject must be a subject without wildcards")
+		case "kafka":
+			check(len(c.Outbox.KafkaBrokers) > 0, "outbox.kafka_brokers must be set when the kafka publisher is used")
+			check(c.Outbox.KafkaTopic != "", "outbox.kafka_topic must be set when the kafka publisher is used")
+		}
+	}
+	check(c.Outbox.HTTPTimeout > 0, "outbox.http_timeout must be positive")
+	check(c.Outbox.PollInterval > 0, "outbox.poll_interval must be positive")
+	check(c.Outbox.Retention > 0, "outbox.retention must be positive")
+	check(c.Outbox.UnpublishedRetention >= c.Outbox.Retention, "outbox.unpublished_retention must not be less than outbox.retention")
+
+	check(c.Events.PollInterval > 0, "events.poll_interval must be positive")
+	check(c.Events.Heartbeat > 0, "events.heartbeat must be positive")
+	check(c.Events.BufferSize > 0, "events.buffer_size must be positive")
+
+	_, err := uuid.Parse(c.Tenancy.DefaultTenantID)
+	check(err == nil, "tenancy.default_tenant_id must be a UUID, got %q", c.Tenancy.DefaultTenantID)
+
+	return errors.Join(errs...)
+}
+
+// DSN returns the connection string for the postgres driver, either the
+// configured URL with sslmode applied or one assembled from the parts.
+func (c DBConfig) DSN() string {
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return c.URL
+		}
+		q := u.Query()
+		if q.Get("sslmode") == "" {
+			q.Set("sslmode", c.SSLMode)
+		}
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	return fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quoteDSN(c.Host), c.Port, quoteDSN(c.User), quoteDSN(c.Password), quoteDSN(c.Name), c.SSLMode,
+	)
+}
+
+// Redacted returns a copy safe to print: passwords and tokens are masked.
+func (c Config) Redacted() Config {
+	if c.DB.Password != "" {
+		c.DB.Password = redacted
+	}
+	if c.DB.URL != "" {
+		c.DB.URL = redactURL(c.DB.URL)
+	}
+	if c.Outbox.HTTPURL != "" {
+		c.Outbox.HTTPURL = redactURL(c.Outbox.HTTPURL)
+	}
+	if c.Outbox.NATSURL != "" {
+		c.Outbox.NATSURL = redactNATSURL(c.Outbox.NATSURL)
+	}
+	if c.Tracing.Headers != "" {
+		c.Tracing.Headers = redacted
+	}
+	if c.Auth.JWTSecret != "" {
+		c.Auth.JWTSecret = redacted
+	}
+	if c.Auth.AdminToken != "" {
+		c.Auth.AdminToken = redacted
+	}
+	return c
+}
+
+// redactURL masks the password and every query parameter value of a URL,
+// either of which may carry credentials (e.g. ?password= or ?token=).
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if u.RawQuery != "" {
+		keys := slices.Sorted(maps.Keys(u.Query()))
+		for i, key := range keys {
+			keys[i] = url.QueryEscape(key) + "=" + redacted
+		}
+		u.RawQuery = strings.Join(keys, "&")
+	}
+	return u.Redacted()
+}
+
+// redactNATSURL masks every server of a comma-separated NATS URL list. NATS
+// also accepts a bare token in place of the user name, which is masked too.
+func redactNATSURL(raw string) string {
+	servers := strings.Split(raw, ",")
+	for i, server := range servers {
+		server = strings.TrimSpace(server)
+		if u, err := url.Parse(server); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); !ok {
+				u.User = url.User("xxxxx")
+				server = u.String()
+			}
+		}
+		servers[i] = redactURL(server)
+	}
+	return strings.Join(servers, ",")
+}
+
+// LogValue makes the configuration safe to pass to slog directly: secrets are
+// always redacted and durations are rendered in their human-readable form.
+func (c Config) LogValue() slog.Value {
+	r := c.Redacted()
+	return slog.GroupValue(
+		slog.Group("http",
+			"port", r.HTTP.Port,
+			"read_header_timeout", r.HTTP.ReadHeaderTimeout.String(),
+			"read_timeout", r.HTTP.ReadTimeout.String(),
+			"write_timeout", r.HTTP.WriteTimeout.String(),
+			"idle_timeout", r.HTTP.IdleTimeout.String(),
+			"shutdown_timeout", r.HTTP.ShutdownTimeout.String(),
+			"trusted_proxies", r.HTTP.TrustedProxies,
+		),
+		slog.Group("db",
+			"url", r.DB.URL,
+			"host", r.DB.Host,
+			"port", r.DB.Port,
+			"user", r.DB.User,
+			"password", r.DB.Password,
+			"name", r.DB.Name,
+			"sslmode", r.DB.SSLMode,
+			"max_open_conns", r.DB.MaxOpenConns,
+			"max_idle_conns", r.DB.MaxIdleConns,
+			"conn_max_lifetime", r.DB.ConnMaxLifetime.String(),
+			"connect_retries", r.DB.ConnectRetries,
+			"retry_interval", r.DB.RetryInterval.String(),
+		),
+		slog.Group("log", "level", r.Log.Level),
+		slog.Group("tracing",
+			"exporter", r.Tracing.Exporter,
+			"endpoint", r.Tracing.Endpoint,
+			"headers", r.Tracing.Headers,
+			"service_name", r.Tracing.ServiceName,
+		),
+		slog.Group("metrics",
+			"port", r.Metrics.Port,
+			"refresh_interval", r.Metrics.RefreshInterval.String(),
+		),
+		slog.Group("auth",
+			"jwt_secret", r.Auth.JWTSecret,
+			"jwks_file", r.Auth.JWKSFile,
+			"jwt_issuer", r.Auth.JWTIssuer,
+			"jwt_audience", r.Auth.JWTAudience,
+			"jwt_leeway", r.Auth.JWTLeeway.String(),
+			"admin_role", r.Auth.AdminRole,
+			"platform_role", r.Auth.PlatformRole,
+			"admin_token", r.Auth.AdminToken,
+		),
+		slog.Group("tenancy", "default_tenant_id", r.Tenancy.DefaultTenantID),
+		slog.Group("rate_limit",
+			"enabled", r.RateLimit.Enabled,
+			"ip", r.RateLimit.IP.String(),
+			"default", r.RateLimit.Default.String(),
+			"reports", r.RateLimit.Reports.String(),
+			"bulk", r.RateLimit.Bulk.String(),
+		),
+		slog.Group("webhooks",
+			"enabled", r.Webhooks.Enabled,
+			"poll_interval", r.Webhooks.PollInterval.String(),
+			"timeout", r.Webhooks.Timeout.String(),
+			"max_attempts", r.Webhooks.MaxAttempts,
+			"backoff_base", r.Webhooks.BackoffBase.String(),
+			"backoff_max", r.Webhooks.BackoffMax.String(),
+			"renewal_lead", r.Webhooks.RenewalLead.String(),
+			"allow_private_targets", r.Webhooks.AllowPrivateTargets,
+		),
+		slog.Group("outbox",
+			"enabled", r.Outbox.Enabled,
+			"publishers", r.Outbox.Publishers,
+			"http_url", r.Outbox.HTTPURL,
+			"http_timeout", r.Outbox.HTTPTimeout.String(),
+			"nats_url", r.Outbox.NATSURL,
+			"nats_subject", r.Outbox.NATSSubject,
+			"kafka_brokers", r.Outbox.KafkaBrokers,
+			"kafka_topic", r.Outbox.KafkaTopic,
+			"poll_interval", r.Outbox.PollInterval.String(),
+			"retention", r.Outbox.Retention.String(),
+			"unpublished_retention", r.Outbox.UnpublishedRetention.String(),
+		),
+		slog.Group("events",
+			"poll_interval", r.Events.PollInterval.String(),
+			"heartbeat", r.Events.Heartbeat.String(),
+			"buffer_size", r.Events.BufferSize,
+		),
+		slog.Group("features", "strict_duplicates", r.Features.StrictDuplicates),
+	)
+}
+
+func applyEnv(v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		tag := v.Type().Field(i).Tag.Get("env")
+
+		if field.Kind() == reflect.Struct && tag == "" {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := os.LookupEnv(tag)
+		if tag == "" || !ok || raw == "" {
+			continue
+		}
+
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(raw)); err != nil {
+				return fmt.Errorf("env %s: %w", tag, err)
+			}
+			continue
+		}
+
+		switch {
+		case field.Type() == reflect.TypeOf(time.Duration(0)):
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("env %s: invalid duration %q", tag, raw)
+			}
+			field.SetInt(int64(d))
+		case field.Kind() == reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("env %s: invalid integer %q", tag, raw)
+			}
+			field.SetInt(int64(n))
+		case field.Kind() == reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("env %s: invalid boolean %q", tag, raw)
+			}
+			field.SetBool(b)
+		case field.Kind() == reflect.String:
+			field.SetString(raw)
+		case field.Type() == reflect.TypeOf([]string(nil)):
+			var items []string
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			field.Set(reflect.ValueOf(items))
+		}
+	}
+	return nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteDSN(s string) string {
+	if s != "" && !strings.ContainsAny(s, ` '\`) {
+		return s
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
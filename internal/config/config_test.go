@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitUnmarshalText(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    RateLimit
+		wantErr bool
+	}{
+		{"60/1m", RateLimit{Requests: 60, Period: time.Minute, Burst: 60}, false},
+		{"60/m", RateLimit{Requests: 60, Period: time.Minute, Burst: 60}, false},
+		{"10/1s,burst=50", RateLimit{Requests: 10, Period: time.Second, Burst: 50}, false},
+		{" 10 / 1s , burst=5 ", RateLimit{Requests: 10, Period: time.Second, Burst: 5}, false},
+		{"1200/1h", RateLimit{Requests: 1200, Period: time.Hour, Burst: 1200}, false},
+		{"off", RateLimit{}, false},
+		{"", RateLimit{}, false},
+		{"60", RateLimit{}, true},
+		{"0/1m", RateLimit{}, true},
+		{"-1/1m", RateLimit{}, true},
+		{"x/1m", RateLimit{}, true},
+		{"60/", RateLimit{}, true},
+		{"60/0s", RateLimit{}, true},
+		{"60/fortnight", RateLimit{}, true},
+		{"60/1m,burst=0", RateLimit{}, true},
+		{"60/1m,burst=x", RateLimit{}, true},
+		{"60/1m,size=5", RateLimit{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got := RateLimit{Requests: 1, Period: time.Second, Burst: 1}
+			err := got.UnmarshalText([]byte(tt.spec))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalText(%q) error = %v, want error %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("UnmarshalText(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitStringRoundTrip(t *testing.T) {
+	for _, spec := range []string{"60/1m", "10/1s,burst=50", "1200/1h", "5/1m30s", "off"} {
+		t.Run(spec, func(t *testing.T) {
+			var limit RateLimit
+			if err := limit.UnmarshalText([]byte(spec)); err != nil {
+				t.Fatal(err)
+			}
+			if got := limit.String(); got != spec {
+				t.Errorf("String() = %q, want %q", got, spec)
+			}
+		})
+	}
+}
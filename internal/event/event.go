@@ -0,0 +1,51 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SubscriptionCreated         = "subscription.created"
+	SubscriptionUpdated         = "subscription.updated"
+	SubscriptionDeleted         = "subscription.deleted"
+	SubscriptionRestored        = "subscription.restored"
+	SubscriptionRenewalUpcoming = "subscription.renewal_upcoming"
+)
+
+var Types = []string{
+	SubscriptionCreated,
+	SubscriptionUpdated,
+	SubscriptionDeleted,
+	SubscriptionRestored,
+	SubscriptionRenewalUpcoming,
+}
+
+// Event is a change in the tenant's data as seen by external consumers. Data
+// holds the JSON representation of the affected record. AggregateID is the
+// record's ID, known once the event is read back from the outbox; brokers
+// partition by it to keep each record's events in order.
+type Event struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	TenantID    uuid.UUID       `json:"-"`
+	AggregateID uint            `json:"-"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func New(eventType string, tenantID uuid.UUID, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		TenantID:   tenantID,
+		OccurredAt: time.Now().UTC(),
+		Data:       raw,
+	}, nil
+}
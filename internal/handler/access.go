@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// authorizeUser writes a 403 response and returns false when the caller may
+// not read or change the data of userID.
+func authorizeUser(c *gin.Context, userID uuid.UUID) bool {
+	principal, ok := auth.FromContext(c.Request.Context())
+	if ok && principal.CanAccess(userID) {
+		return true
+	}
+
+	logger.FromContext(c.Request.Context()).Warn("access to another user's data denied", "user_id", userID)
+	c.JSON(http.StatusForbidden, gin.H{"error": "access to another user's data is forbidden"})
+	return false
+}
+
+// defaultUserID fills an omitted user_id with the caller's own ID, so normal
+// users do not have to repeat the subject of their token in request bodies.
+func defaultUserID(c *gin.Context, userID *uuid.UUID) {
+	principal, ok := auth.FromContext(c.Request.Context())
+	if ok && !principal.Unrestricted() && *userID == uuid.Nil {
+		*userID = principal.UserID
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"subscription-aggregator/internal/auth"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestAuthorizeUser(t *testing.T) {
+	self := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	other := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		userID uuid.UUID
+		want   bool
+	}{
+		{"anonymous", context.Background(), self, false},
+		{"own data", auth.WithPrincipal(context.Background(), auth.Principal{UserID: self, Method: auth.MethodJWT}), self, true},
+		{"another user's data", auth.WithPrincipal(context.Background(), auth.Principal{UserID: self, Method: auth.MethodJWT}), other, false},
+		{"admin", auth.WithPrincipal(context.Background(), auth.Principal{UserID: self, Admin: true, Method: auth.MethodJWT}), other, true},
+		{"unbound API key", auth.WithPrincipal(context.Background(), auth.Principal{Method: auth.MethodAPIKey}), other, true},
+		{"user-bound API key", auth.WithPrincipal(context.Background(), auth.Principal{UserID: self, Method: auth.MethodAPIKey}), other, false},
+		{"user-bound admin API key", auth.WithPrincipal(context.Background(), auth.Principal{UserID: self, Admin: true, Method: auth.MethodAPIKey, Scopes: []string{auth.ScopeAdmin}}), other, false},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(tt.ctx)
+
+			if got := authorizeUser(c, tt.userID); got != tt.want {
+				t.Fatalf("authorizeUser() = %v, want %v", got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusForbidden)
+			}
+			if tt.want && w.Body.Len() != 0 {
+				t.Fatalf("unexpected response body %q", w.Body)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
-	"log"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/logger"
 	"subscription-aggregator/internal/model"
 	"subscription-aggregator/internal/repository"
 	"time"
@@ -11,208 +16,385 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // @Summary	Создание подписки
 // @Accept		json
 // @Produce	json
+// @Param		Authorization	header		string						true	"Bearer-токен (JWT)"
 // @Param		subscription	body		swagger.SubscriptionExample	true	"Данные подписки"
 // @Success	200				{object}	swagger.MessageResponse
 // @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	409				{object}	swagger.ErrorResponse409
+// @Failure	429				{object}	swagger.ErrorResponse429
 // @Failure	500				{object}	swagger.ErrorResponse500
 // @Router		/create [post]
 func CreateSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
 	var sub model.Subscription
 
-	log.Println("[CreateSubscription] received request")
+	log.Info("received request")
 
 	if err := c.ShouldBindJSON(&sub); err != nil {
-		log.Printf("[CreateSubscription] JSON bind error: %v\n", err)
+		log.Warn("JSON bind error", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
 		return
 	}
+	defaultUserID(c, &sub.UserID)
+	if err := validateSubscription(sub); err != nil {
+		log.Warn("validation error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !authorizeUser(c, sub.UserID) {
+		return
+	}
 
-	log.Printf(
-		"[CreateSubscription] creating subscription for user_id=%s, service_name=%s\n",
-		sub.UserID,
-		sub.ServiceName,
-	)
-
-	if err := repository.DB.Create(&sub).Error; err != nil {
-		log.Printf("[CreateSubscription] DB create error: %v\n", err)
+	log.Info("creating subscription", "user_id", sub.UserID, "service_name", sub.ServiceName)
+
+	var overlapping *model.Subscription
+	err := repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if StrictDuplicates {
+			if err := repository.LockUserSubscriptions(tx, sub.UserID); err != nil {
+				return err
+			}
+			var err error
+			if overlapping, err = findOverlappingSubscription(tx, sub); err != nil || overlapping != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&sub).Error; err != nil {
+			return err
+		}
+		return repository.AddToOutbox(tx, event.SubscriptionCreated, sub)
+	})
+	if err != nil {
+		log.Error("DB create error", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create record in db"})
 		return
 	}
+	if overlapping != nil {
+		log.Warn("overlaps with subscription", "id", overlapping.ID)
+		c.JSON(http.StatusConflict, gin.H{"error": "overlapping active subscription exists", "id": overlapping.ID})
+		return
+	}
 
-	log.Printf("[CreateSubscription] successfully created subscription ID=%d\n", sub.ID)
+	log.Info("successfully created subscription", "id", sub.ID)
 	c.JSON(http.StatusOK, gin.H{"message": "created", "id": sub.ID})
 }
 
 // @Summary	Получить данные подписки по ID
 // @Produce	json
-// @Param		id	path		int	true	"ID подписки"	default(1)
-// @Success	200	{object}	swagger.SubscriptionResponse
-// @Failure	400	{object}	swagger.ErrorResponse400
-// @Failure	404	{object}	swagger.ErrorResponse404
-// @Failure	500	{object}	swagger.ErrorResponse500
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		id				path		int		true	"ID подписки"	default(1)
+// @Success	200				{object}	swagger.SubscriptionResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
 // @Router		/read/{id} [get]
 func ReadSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		log.Printf("[ReadSubscription] invalid id param: %v\n", err)
+		log.Warn("invalid id param", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
 
 	var sub model.Subscription
-	log.Printf("[ReadSubscription] reading subscription id=%d\n", id)
+	log.Info("reading subscription", "id", id)
 
-	err = repository.DB.First(&sub, id).Error
+	err = repository.DB.WithContext(ctx).Scopes(repository.Owned(ctx)).First(&sub, id).Error
 	if err == gorm.ErrRecordNotFound {
-		log.Printf("[ReadSubscription] record not found id=%d\n", id)
+		log.Warn("record not found", "id", id)
 		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
 		return
 	}
 	if err != nil {
-		log.Printf("[ReadSubscription] DB error: %v\n", err)
+		log.Error("DB error", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to find record in db"})
 		return
 	}
 
-	log.Printf("[ReadSubscription] found subscription id=%d\n", sub.ID)
+	log.Info("found subscription", "id", sub.ID)
 	c.JSON(http.StatusOK, sub)
 }
 
 // @Summary	Обновить подписку по ID
 // @Accept		json
 // @Produce	json
+// @Param		Authorization	header		string								true	"Bearer-токен (JWT)"
 // @Param		id				path		int									true	"ID подписки"	default(1)
 // @Param		subscription	body		swagger.UpdateSubscriptionExample	true	"Новые данные подписки"
 // @Success	200				{object}	swagger.MessageResponse
 // @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
 // @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	409				{object}	swagger.ErrorResponse409
+// @Failure	429				{object}	swagger.ErrorResponse429
 // @Failure	500				{object}	swagger.ErrorResponse500
 // @Router		/update/{id} [put]
 func UpdateSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		log.Printf("[UpdateSubscription] invalid id param: %v\n", err)
+		log.Warn("invalid id param", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
 
 	var input map[string]interface{}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		log.Printf("[UpdateSubscription] JSON bind error: %v\n", err)
+		log.Warn("JSON bind error", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind json"})
 		return
 	}
+	// Clients often send back the whole record; the id in the path wins.
+	delete(input, "id")
+	for field := range input {
+		if !updatableFields[field] {
+			log.Warn("unknown update field", "field", field)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown field " + field})
+			return
+		}
+	}
+	if raw, ok := input["user_id"]; ok {
+		s, _ := raw.(string)
+		userID, err := uuid.Parse(s)
+		if err != nil {
+			log.Warn("invalid user_id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		if !authorizeUser(c, userID) {
+			return
+		}
+	}
 
-	log.Printf("[UpdateSubscription] updating subscription id=%d with data: %+v\n", id, input)
+	log.Info("updating subscription", "id", id, "fields", updateFields(input))
 
-	result := repository.DB.Model(&model.Subscription{}).Where("id = ?", id).Updates(input)
-	if result.RowsAffected == 0 {
-		log.Printf("[UpdateSubscription] no record found to update for id=%d\n", id)
+	// The row is locked and re-validated as it will look after the update, so
+	// that e.g. an end_date alone is checked against the stored start_date.
+	var (
+		invalid     error
+		overlapping *model.Subscription
+	)
+	err = repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sub model.Subscription
+		err := tx.Scopes(repository.Owned(ctx)).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&sub, id).
+			Error
+		if err != nil {
+			return err
+		}
+		if invalid = mergeSubscriptionUpdate(&sub, input); invalid != nil {
+			return nil
+		}
+		if StrictDuplicates {
+			if err := repository.LockUserSubscriptions(tx, sub.UserID); err != nil {
+				return err
+			}
+			if overlapping, err = findOverlappingSubscription(tx, sub); err != nil || overlapping != nil {
+				return err
+			}
+		}
+
+		if err := saveSubscriptionUpdate(tx, &sub, updateFields(input)); err != nil {
+			return err
+		}
+		return repository.AddToOutbox(tx, event.SubscriptionUpdated, sub)
+	})
+	if err == gorm.ErrRecordNotFound {
+		log.Warn("no record found to update", "id", id)
 		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
 		return
 	}
-	if result.Error != nil {
-		log.Printf("[UpdateSubscription] DB update error: %v\n", result.Error)
+	if err != nil {
+		log.Error("DB update error", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update record in db"})
 		return
 	}
+	if invalid != nil {
+		log.Warn("invalid subscription", "error", invalid)
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Error()})
+		return
+	}
+	if overlapping != nil {
+		log.Warn("overlaps with subscription", "id", overlapping.ID)
+		c.JSON(http.StatusConflict, gin.H{"error": "overlapping active subscription exists", "id": overlapping.ID})
+		return
+	}
 
-	log.Printf("[UpdateSubscription] successfully updated subscription id=%d\n", id)
+	log.Info("successfully updated subscription", "id", id)
 	c.JSON(http.StatusOK, gin.H{"message": "updated"})
 }
 
 // @Summary	Удалить подписку по ID
 // @Produce	json
-// @Param		id	path		int	true	"ID подписки"	default(1)
-// @Success	200	{object}	swagger.MessageResponse
-// @Failure	400	{object}	swagger.ErrorResponse400
-// @Failure	404	{object}	swagger.ErrorResponse404
-// @Failure	500	{object}	swagger.ErrorResponse500
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		id				path		int		true	"ID подписки"	default(1)
+// @Success	200				{object}	swagger.MessageResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
 // @Router		/delete/{id} [delete]
 func DeleteSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		log.Printf("[DeleteSubscription] invalid id param: %v\n", err)
+		log.Warn("invalid id param", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
 
-	log.Printf("[DeleteSubscription] deleting subscription id=%d\n", id)
+	log.Info("deleting subscription", "id", id)
 
-	result := repository.DB.Delete(&model.Subscription{}, id)
-	if result.RowsAffected == 0 {
-		log.Printf("[DeleteSubscription] record not found id=%d\n", id)
-		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+	var sub model.Subscription
+	var rows int64
+	err = repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Scopes(repository.Owned(ctx)).Clauses(clause.Returning{}).Delete(&sub, id)
+		if rows = result.RowsAffected; result.Error != nil || rows == 0 {
+			return result.Error
+		}
+		return repository.AddToOutbox(tx, event.SubscriptionDeleted, sub)
+	})
+	if err != nil {
+		log.Error("DB delete error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete record from db"})
 		return
 	}
-	if result.Error != nil {
-		log.Printf("[DeleteSubscription] DB delete error: %v\n", result.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete record from db"})
+	if rows == 0 {
+		log.Warn("record not found", "id", id)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
 		return
 	}
 
-	log.Printf("[DeleteSubscription] successfully deleted id=%d\n", id)
+	log.Info("successfully deleted", "id", id)
 	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
 
+// @Summary	Восстановить удалённую подписку по ID
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		id				path		int		true	"ID подписки"	default(1)
+// @Success	200				{object}	swagger.SubscriptionResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/restore/{id} [post]
+func RestoreSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Warn("invalid id param", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	log.Info("restoring subscription", "id", id)
+
+	var sub model.Subscription
+	var rows int64
+	err = repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&sub).Scopes(repository.Owned(ctx)).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if rows = result.RowsAffected; result.Error != nil || rows == 0 {
+			return result.Error
+		}
+		return repository.AddToOutbox(tx, event.SubscriptionRestored, sub)
+	})
+	if err != nil {
+		log.Error("DB update error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update record in db"})
+		return
+	}
+	if rows == 0 {
+		log.Warn("no deleted record found to restore", "id", id)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+		return
+	}
+
+	log.Info("successfully restored subscription", "id", id)
+	c.JSON(http.StatusOK, sub)
+}
+
 // @Summary	Получение списка подписок (есть фильтрация по ID пользователя и по названию сервиса)
 // @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
 // @Param		user_id			query		string	false	"ID пользователя"	default(11111111-1111-1111-1111-111111111111)
 // @Param		service_name	query		string	false	"Название сервиса"	default(Netflix)
 // @Success	200				{array}		swagger.SubscriptionResponse
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	429				{object}	swagger.ErrorResponse429
 // @Failure	500				{object}	swagger.ErrorResponse500
 // @Failure	404				{object}	swagger.ErrorResponse404
 // @Router		/list [get]
 func ListSubscriptions(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
 	userID := c.Query("user_id")
 	serviceName := c.Query("service_name")
 
-	log.Printf(
-		"[ListSubscriptions] fetching subscriptions for user_id=%s, service_name=%s\n",
-		userID,
-		serviceName,
-	)
-
-	query := repository.DB
-	if userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
-	if serviceName != "" {
-		query = query.Where("service_name = ?", serviceName)
-	}
+	log.Info("fetching subscriptions", "user_id", userID, "service_name", serviceName)
 
 	var subs []model.Subscription
-	if err := query.Find(&subs).Error; err != nil {
-		log.Printf("[ListSubscriptions] DB error: %v\n", err)
+	if err := repository.FilterSubscriptions(ctx, userID, serviceName).Find(&subs).Error; err != nil {
+		log.Error("DB error", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
 		return
 	}
 	if len(subs) == 0 {
-		log.Printf("[ListSubscriptions] no records found\n")
+		log.Warn("no records found")
 		c.Status(http.StatusNotFound)
 		return
 	}
 
-	log.Printf("[ListSubscriptions] found %d subscriptions\n", len(subs))
+	log.Info("found subscriptions", "count", len(subs))
 	c.JSON(http.StatusOK, subs)
 }
 
 // @Summary	Получение суммы стоимости всех подписок за выбранный период по ID пользователя и имени сервиса
 // @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
 // @Param		user_id			query		string	true	"ID пользователя"					default(11111111-1111-1111-1111-111111111111)
 // @Param		service_name	query		string	true	"Название сервиса"					default(Netflix)
 // @Param		period_start	query		string	true	"Начало периода в формате MM-YYYY"	default(06-2025)
 // @Param		period_end		query		string	true	"Конец периода в формате MM-YYYY"	default(08-2025)
 // @Success	200				{object}	swagger.SumResponse
 // @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
 // @Failure	500				{object}	swagger.ErrorResponse500
 // @Router		/sum [get]
 func SumSubscriptionsPrice(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
 	var sum int
 
 	sumReq := struct {
@@ -223,41 +405,38 @@ func SumSubscriptionsPrice(c *gin.Context) {
 	}{}
 
 	if err := c.ShouldBindQuery(&sumReq); err != nil {
-		log.Printf("[SumSubscriptionsPrice] bind query error: %v\n", err)
+		log.Warn("bind query error", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
 
-	log.Printf(
-		"[SumSubscriptionsPrice] calculating sum for user_id=%s, service_name=%s, start=%s, end=%s\n",
-		sumReq.UserID,
-		sumReq.ServiceName,
-		sumReq.PeriodStart,
-		sumReq.PeriodEnd,
-	)
+	log.Info("calculating sum", "user_id", sumReq.UserID, "service_name", sumReq.ServiceName, "start", sumReq.PeriodStart, "end", sumReq.PeriodEnd)
 
 	userID, err := uuid.Parse(sumReq.UserID)
 	if err != nil {
-		log.Printf("[SumSubscriptionsPrice] invalid user_id: %v\n", err)
+		log.Warn("invalid user_id", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
 		return
 	}
+	if !authorizeUser(c, userID) {
+		return
+	}
 
 	periodStart, err := time.Parse("01-2006", sumReq.PeriodStart)
 	if err != nil {
-		log.Printf("[SumSubscriptionsPrice] invalid period_start: %v\n", err)
+		log.Warn("invalid period_start", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_start"})
 		return
 	}
 
 	periodEnd, err := time.Parse("01-2006", sumReq.PeriodEnd)
 	if err != nil {
-		log.Printf("[SumSubscriptionsPrice] invalid period_end: %v\n", err)
+		log.Warn("invalid period_end", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_end"})
 		return
 	}
 
-	err = repository.DB.Model(&model.Subscription{}).
+	err = repository.DB.WithContext(ctx).Model(&model.Subscription{}).
 		Select("COALESCE(SUM(price), 0)").
 		Where("user_id = ?", userID).
 		Where("LOWER(service_name) = LOWER(?)", sumReq.ServiceName).
@@ -265,11 +444,81 @@ func SumSubscriptionsPrice(c *gin.Context) {
 		Scan(&sum).
 		Error
 	if err != nil {
-		log.Printf("[SumSubscriptionsPrice] DB error: %v\n", err)
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get sum"})
+		return
+	}
+
+	tenant, err := repository.CurrentTenant(ctx)
+	if err != nil {
+		log.Error("DB tenant error", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get sum"})
 		return
 	}
 
-	log.Printf("[SumSubscriptionsPrice] total sum: %d\n", sum)
-	c.JSON(http.StatusOK, gin.H{"sum_price": sum})
+	log.Info("total sum", "sum", sum)
+	c.JSON(http.StatusOK, gin.H{"sum_price": sum, "currency": tenant.DefaultCurrency})
+}
+
+func validateSubscription(sub model.Subscription) error {
+	switch {
+	case strings.TrimSpace(sub.ServiceName) == "":
+		return errors.New("service_name is required")
+	case sub.UserID == uuid.Nil:
+		return errors.New("user_id is required")
+	case sub.StartDate.IsZero():
+		return errors.New("start_date is required")
+	case sub.EndDate != nil && sub.EndDate.Before(sub.StartDate.Time):
+		return errors.New("end_date is before start_date")
+	case sub.BillingCycle != "" && model.BillingCycleMonths[sub.BillingCycle] == 0:
+		return errors.New("invalid billing_cycle")
+	}
+	return nil
+}
+
+// updatableFields are the columns UpdateSubscription accepts; the keys of the
+// input double as column names.
+var updatableFields = map[string]bool{
+	"service_name":  true,
+	"category":      true,
+	"price":         true,
+	"user_id":       true,
+	"start_date":    true,
+	"end_date":      true,
+	"billing_cycle": true,
+}
+
+// mergeSubscriptionUpdate applies the update input to sub and validates the
+// result.
+func mergeSubscriptionUpdate(sub *model.Subscription, input map[string]interface{}) error {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, sub); err != nil {
+		return errors.New("invalid update data")
+	}
+	// An empty cycle means "default" on create, but a stored row always has
+	// one, so here it can only come from the input.
+	if sub.BillingCycle == "" {
+		return errors.New("invalid billing_cycle")
+	}
+	return validateSubscription(*sub)
+}
+
+// saveSubscriptionUpdate writes the merged row, limited to the fields the
+// client sent, and reads the stored row back into sub. Writing the struct
+// rather than the raw input stores typed values, e.g. dates instead of
+// "MM-YYYY" strings.
+func saveSubscriptionUpdate(tx *gorm.DB, sub *model.Subscription, fields []string) error {
+	return tx.Model(sub).Clauses(clause.Returning{}).Select(fields).Updates(sub).Error
+}
+
+func updateFields(input map[string]interface{}) []string {
+	fields := make([]string, 0, len(input))
+	for field := range input {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
 }
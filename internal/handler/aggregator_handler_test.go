@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"strconv"
+	"strings"
+	"subscription-aggregator/internal/model"
+	monthyear "subscription-aggregator/pkg/month-year"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestSaveSubscriptionUpdateEndDateOnly(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		sql  string
+		vars []interface{}
+	)
+	err = db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		sql, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub := model.Subscription{
+		ID:           7,
+		ServiceName:  "Netflix",
+		Price:        400,
+		UserID:       uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		StartDate:    monthyear.MonthYear{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		BillingCycle: model.BillingMonthly,
+	}
+	input := map[string]interface{}{"end_date": "06-2025"}
+	if err := mergeSubscriptionUpdate(&sub, input); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if err := saveSubscriptionUpdate(db, &sub, updateFields(input)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	set := sql[:strings.Index(sql, " WHERE ")]
+	match := regexp.MustCompile(`"end_date"=\$(\d+)`).FindStringSubmatch(set)
+	if match == nil {
+		t.Fatalf("end_date not written: %s", sql)
+	}
+	for _, column := range []string{"service_name", "price", "user_id", "start_date", "billing_cycle"} {
+		if strings.Contains(set, `"`+column+`"`) {
+			t.Errorf("%s written although not sent: %s", column, sql)
+		}
+	}
+
+	index, _ := strconv.Atoi(match[1])
+	value := vars[index-1]
+	if valuer, ok := value.(driver.Valuer); ok {
+		if value, err = valuer.Value(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if value != "2025-06-01" {
+		t.Errorf("end_date = %#v, want the date 2025-06-01", value)
+	}
+}
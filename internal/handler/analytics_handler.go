@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary	Количество подписчиков и выручка по каждому сервису за каждый месяц периода
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		period_start	query		string	true	"Начало периода в формате MM-YYYY"	default(06-2025)
+// @Param		period_end		query		string	true	"Конец периода в формате MM-YYYY"	default(08-2025)
+// @Success	200				{array}		swagger.ServiceMonthStatsResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/analytics/services [get]
+func GetServiceAnalytics(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	from, to, ok := bindPeriod(c)
+	if !ok {
+		return
+	}
+
+	stats, err := repository.GetServiceMonthStats(ctx, from, to)
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get analytics"})
+		return
+	}
+
+	log.Info("found service months", "count", len(stats))
+	c.JSON(http.StatusOK, stats)
+}
+
+// @Summary	Средняя, минимальная и максимальная цена подписки по каждому сервису за период
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		period_start	query		string	true	"Начало периода в формате MM-YYYY"	default(06-2025)
+// @Param		period_end		query		string	true	"Конец периода в формате MM-YYYY"	default(08-2025)
+// @Success	200				{array}		swagger.ServicePriceStatsResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/analytics/prices [get]
+func GetPriceAnalytics(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	from, to, ok := bindPeriod(c)
+	if !ok {
+		return
+	}
+
+	stats, err := repository.GetServicePriceStats(ctx, from, to)
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get analytics"})
+		return
+	}
+
+	log.Info("found services", "count", len(stats))
+	c.JSON(http.StatusOK, stats)
+}
+
+// @Summary	Новые и завершившиеся (отток) подписки по месяцам периода
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		period_start	query		string	true	"Начало периода в формате MM-YYYY"	default(06-2025)
+// @Param		period_end		query		string	true	"Конец периода в формате MM-YYYY"	default(08-2025)
+// @Success	200				{array}		swagger.MonthMovementResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/analytics/movements [get]
+func GetMovementAnalytics(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	from, to, ok := bindPeriod(c)
+	if !ok {
+		return
+	}
+
+	movements, err := repository.GetMonthMovements(ctx, from, to)
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get analytics"})
+		return
+	}
+
+	log.Info("found months", "count", len(movements))
+	c.JSON(http.StatusOK, movements)
+}
+
+func bindPeriod(c *gin.Context) (monthyear.MonthYear, monthyear.MonthYear, bool) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	periodStart, err := monthyear.Parse(c.Query("period_start"))
+	if err != nil {
+		log.Warn("invalid period_start", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_start"})
+		return monthyear.MonthYear{}, monthyear.MonthYear{}, false
+	}
+
+	periodEnd, err := monthyear.Parse(c.Query("period_end"))
+	if err != nil || periodEnd.Before(periodStart.Time) {
+		log.Warn("invalid period_end", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period_end"})
+		return monthyear.MonthYear{}, monthyear.MonthYear{}, false
+	}
+
+	log.Info("period parsed", "start", periodStart, "end", periodEnd)
+	return periodStart, periodEnd, true
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAnomalyFactor    = 5.0
+	defaultAnomalyMinSample = 3
+)
+
+// @Summary	Поиск подписок, цена которых сильно отличается от медианы по подпискам того же сервиса, активным в том же месяце
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		period_start	query		string	true	"Начало периода в формате MM-YYYY"						default(06-2025)
+// @Param		period_end		query		string	true	"Конец периода в формате MM-YYYY"						default(08-2025)
+// @Param		factor			query		number	false	"Во сколько раз цена должна отличаться от медианы"		default(5)
+// @Param		min_sample		query		int		false	"Минимальное количество подписок для расчёта медианы"	default(3)
+// @Success	200				{object}	swagger.PriceAnomalyReportResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/analytics/anomalies [get]
+func GetPriceAnomalies(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	from, to, ok := bindPeriod(c)
+	if !ok {
+		return
+	}
+
+	factor := defaultAnomalyFactor
+	if raw := c.Query("factor"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) || parsed <= 1 {
+			log.Warn("invalid factor", "value", raw)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid factor"})
+			return
+		}
+		factor = parsed
+	}
+
+	minSample := defaultAnomalyMinSample
+	if raw := c.Query("min_sample"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			log.Warn("invalid min_sample", "value", raw)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_sample"})
+			return
+		}
+		minSample = parsed
+	}
+
+	anomalies, err := repository.GetPriceAnomalies(ctx, from, to, factor, minSample)
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get anomalies"})
+		return
+	}
+
+	log.Info("found anomalies", "count", len(anomalies))
+	c.JSON(http.StatusOK, gin.H{
+		"factor":     factor,
+		"min_sample": minSample,
+		"anomalies":  anomalies,
+	})
+}
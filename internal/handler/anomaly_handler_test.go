@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetPriceAnomaliesRejectsInvalidFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/analytics/anomalies", GetPriceAnomalies)
+
+	for _, factor := range []string{"abc", "1", "0.5", "-3", "NaN", "nan", "Inf", "+Inf", "-Inf", "1e400"} {
+		t.Run(factor, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/analytics/anomalies?period_start=06-2025&period_end=08-2025&factor="+factor, nil)
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("factor %s: status %d, want 400", factor, w.Code)
+			}
+		})
+	}
+}
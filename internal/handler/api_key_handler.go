@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type apiKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	UserID    *uuid.UUID `json:"user_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// @Summary	Создание API-ключа для сервисных клиентов (ключ возвращается один раз)
+// @Accept		json
+// @Produce	json
+// @Param		Authorization	header		string					false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string					false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		key				body		swagger.APIKeyExample	true	"Параметры ключа"
+// @Success	200				{object}	swagger.APIKeyIssuedResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/api-keys/create [post]
+func CreateAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var req apiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn("JSON bind error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
+		return
+	}
+	if err := validateAPIKeyRequest(req); err != nil {
+		log.Warn("invalid api key request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		log.Error("key generation error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate key"})
+		return
+	}
+
+	apiKey := model.APIKey{
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    prefix,
+		KeyHash:   auth.HashAPIKey(key),
+		Scopes:    req.Scopes,
+		UserID:    req.UserID,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	log.Info("creating api key", "name", apiKey.Name, "scopes", req.Scopes, "user_id", req.UserID)
+
+	if err := repository.DB.WithContext(ctx).Create(&apiKey).Error; err != nil {
+		log.Error("DB create error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create record in db"})
+		return
+	}
+
+	log.Info("created api key", "id", apiKey.ID, "prefix", prefix)
+	c.JSON(http.StatusOK, gin.H{"message": "created", "id": apiKey.ID, "key": key, "prefix": prefix})
+}
+
+// @Summary	Список API-ключей (без секретов)
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Success	200				{array}		swagger.APIKeyResponse
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/api-keys/list [get]
+func ListAPIKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var keys []model.APIKey
+	if err := repository.DB.WithContext(ctx).Order("id").Find(&keys).Error; err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
+		return
+	}
+
+	log.Info("found api keys", "count", len(keys))
+	c.JSON(http.StatusOK, keys)
+}
+
+// @Summary	Отзыв API-ключа по ID
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		id				path		int		true	"ID ключа"	default(1)
+// @Success	200				{object}	swagger.MessageResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/api-keys/revoke/{id} [post]
+func RevokeAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Warn("invalid id param", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	log.Info("revoking api key", "id", id)
+
+	result := repository.DB.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Error("DB update error", "error", result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update record in db"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Warn("no active key found", "id", id)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+		return
+	}
+
+	log.Info("revoked api key", "id", id)
+	c.JSON(http.StatusOK, gin.H{"message": "revoked"})
+}
+
+// @Summary	Ротация API-ключа: выпускается новый секрет, старый перестаёт действовать сразу
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		id				path		int		true	"ID ключа"	default(1)
+// @Success	200				{object}	swagger.APIKeyIssuedResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	409				{object}	swagger.ErrorResponse409
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/api-keys/rotate/{id} [post]
+func RotateAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Warn("invalid id param", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		log.Error("key generation error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate key"})
+		return
+	}
+
+	log.Info("rotating api key", "id", id)
+
+	err = repository.RotateAPIKey(ctx, uint(id), auth.HashAPIKey(key), prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Warn("no key found", "id", id)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+		return
+	}
+	if errors.Is(err, repository.ErrInactiveAPIKey) {
+		log.Warn("refused to rotate inactive key", "id", id)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Error("DB update error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update record in db"})
+		return
+	}
+
+	log.Info("rotated api key", "id", id, "prefix", prefix)
+	c.JSON(http.StatusOK, gin.H{"message": "rotated", "id": id, "key": key, "prefix": prefix})
+}
+
+func validateAPIKeyRequest(req apiKeyRequest) error {
+	switch {
+	case strings.TrimSpace(req.Name) == "":
+		return errors.New("name is required")
+	case len(req.Scopes) == 0:
+		return errors.New("at least one scope is required")
+	case req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()):
+		return errors.New("expires_at must be in the future")
+	}
+
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !slices.Contains(auth.AllScopes, scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+		if seen[scope] {
+			return fmt.Errorf("duplicate scope %q", scope)
+		}
+		seen[scope] = true
+	}
+	return nil
+}
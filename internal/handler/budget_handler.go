@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	BudgetStatusOK       = "ok"
+	BudgetStatusWarning  = "warning"
+	BudgetStatusExceeded = "exceeded"
+
+	defaultWarningThreshold = 80
+	// forecastTrendMonths is how many past months the rate of new
+	// subscriptions used for forecasts is averaged over.
+	forecastTrendMonths = 3
+)
+
+type budgetRequest struct {
+	UserID           uuid.UUID `json:"user_id"`
+	ServiceName      string    `json:"service_name"`
+	Category         string    `json:"category"`
+	Amount           uint      `json:"amount"`
+	WarningThreshold *uint     `json:"warning_threshold"`
+}
+
+// budgetMonth reports the spend of one month. Spend covers the subscriptions
+// known today; for future months Projected adds the subscriptions expected to
+// be started by then, and Status is evaluated against it.
+type budgetMonth struct {
+	Month     monthyear.MonthYear `json:"month"`
+	Spend     int                 `json:"spend"`
+	Projected *int                `json:"projected,omitempty"`
+	Status    string              `json:"status"`
+	Forecast  bool                `json:"forecast"`
+}
+
+// @Summary	Создание бюджета пользователя (общего, по сервису или по категории); warning_threshold по умолчанию 80
+// @Accept		json
+// @Produce	json
+// @Param		Authorization	header		string					true	"Bearer-токен (JWT)"
+// @Param		budget			body		swagger.BudgetExample	true	"Данные бюджета"
+// @Success	200				{object}	swagger.MessageResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/budget/create [post]
+func CreateBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var req budgetRequest
+
+	log.Info("received request")
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn("JSON bind error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
+		return
+	}
+
+	budget := model.Budget{
+		UserID:           req.UserID,
+		ServiceName:      strings.TrimSpace(req.ServiceName),
+		Category:         strings.TrimSpace(req.Category),
+		Amount:           req.Amount,
+		WarningThreshold: defaultWarningThreshold,
+	}
+	if req.WarningThreshold != nil {
+		budget.WarningThreshold = *req.WarningThreshold
+	}
+	defaultUserID(c, &budget.UserID)
+	if budget.UserID == uuid.Nil || budget.Amount == 0 || budget.WarningThreshold > 100 {
+		log.Warn("invalid budget", "user_id", budget.UserID, "amount", budget.Amount, "warning_threshold", budget.WarningThreshold)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget"})
+		return
+	}
+	if budget.ServiceName != "" && budget.Category != "" {
+		log.Warn("budget with both service_name and category")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "budget may be set per service_name or per category, not both"})
+		return
+	}
+	if !authorizeUser(c, budget.UserID) {
+		return
+	}
+
+	log.Info("creating budget", "user_id", budget.UserID, "service_name", budget.ServiceName, "category", budget.Category)
+
+	if err := repository.DB.WithContext(ctx).Create(&budget).Error; err != nil {
+		log.Error("DB create error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create record in db"})
+		return
+	}
+
+	log.Info("successfully created budget", "id", budget.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "created", "id": budget.ID})
+}
+
+// @Summary	Получение списка бюджетов пользователя
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		user_id			query		string	true	"ID пользователя"	default(11111111-1111-1111-1111-111111111111)
+// @Success	200				{array}		swagger.BudgetResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/budget/list [get]
+func ListBudgets(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		log.Warn("invalid user_id", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+	if !authorizeUser(c, userID) {
+		return
+	}
+
+	log.Info("fetching budgets", "user_id", userID)
+
+	var budgets []model.Budget
+	if err := repository.DB.WithContext(ctx).Where("user_id = ?", userID).Find(&budgets).Error; err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
+		return
+	}
+
+	log.Info("found budgets", "count", len(budgets))
+	c.JSON(http.StatusOK, budgets)
+}
+
+// @Summary	Удалить бюджет по ID
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		id				path		int		true	"ID бюджета"	default(1)
+// @Success	200				{object}	swagger.MessageResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/budget/delete/{id} [delete]
+func DeleteBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Warn("invalid id param", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	log.Info("deleting budget", "id", id)
+
+	result := repository.DB.WithContext(ctx).Scopes(repository.Owned(ctx)).Delete(&model.Budget{}, id)
+	if result.Error != nil {
+		log.Error("DB delete error", "error", result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete record from db"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Warn("record not found", "id", id)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+		return
+	}
+
+	log.Info("successfully deleted", "id", id)
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+// @Summary	Оценка фактических и прогнозных трат относительно бюджета по месяцам
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		id				path		int		true	"ID бюджета"						default(1)
+// @Param		period_start	query		string	true	"Начало периода в формате MM-YYYY"	default(06-2025)
+// @Param		period_end		query		string	true	"Конец периода в формате MM-YYYY"	default(12-2025)
+// @Success	200				{object}	swagger.BudgetEvaluationResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/budget/evaluate/{id} [get]
+func EvaluateBudget(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Warn("invalid id param", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	periodStart, periodEnd, ok := bindPeriod(c)
+	if !ok {
+		return
+	}
+
+	var budget model.Budget
+	err = repository.DB.WithContext(ctx).Scopes(repository.Owned(ctx)).First(&budget, id).Error
+	if err == gorm.ErrRecordNotFound {
+		log.Warn("record not found", "id", id)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+		return
+	}
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to find record in db"})
+		return
+	}
+
+	log.Info("evaluating budget", "id", budget.ID, "start", periodStart, "end", periodEnd)
+
+	filter := repository.SpendFilter{ServiceName: budget.ServiceName, Category: budget.Category}
+	spend, err := repository.GetMonthlySpend(ctx, budget.UserID, filter, periodStart, periodEnd)
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get monthly spend"})
+		return
+	}
+
+	currentMonth := monthyear.Current()
+
+	var newSpendRate float64
+	if periodEnd.After(currentMonth.Time) {
+		newSpendRate, err = repository.GetNewSpendRate(ctx, budget.UserID, filter, currentMonth.AddMonths(-forecastTrendMonths), currentMonth)
+		if err != nil {
+			log.Error("DB error", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get monthly spend"})
+			return
+		}
+	}
+
+	months := make([]budgetMonth, 0, len(spend))
+	for _, s := range spend {
+		month := budgetMonth{Month: s.Month, Spend: s.Total, Status: budgetStatus(budget, s.Total)}
+		if ahead := currentMonth.MonthsUntil(s.Month); ahead > 0 {
+			projected := s.Total + int(math.Round(newSpendRate*float64(ahead)))
+			month.Forecast = true
+			month.Projected = &projected
+			month.Status = budgetStatus(budget, projected)
+		}
+		months = append(months, month)
+	}
+
+	log.Info("evaluated months", "count", len(months))
+	c.JSON(http.StatusOK, gin.H{"budget": budget, "months": months})
+}
+
+func budgetStatus(budget model.Budget, spend int) string {
+	switch {
+	case spend > int(budget.Amount):
+		return BudgetStatusExceeded
+	case spend*100 >= int(budget.Amount*budget.WarningThreshold):
+		return BudgetStatusWarning
+	default:
+		return BudgetStatusOK
+	}
+}
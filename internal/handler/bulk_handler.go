@@ -0,0 +1,459 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const BulkMaxItems = 1000
+
+type bulkItemResult struct {
+	Index  int    `json:"index"`
+	ID     uint   `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type bulkUpdateRequest struct {
+	Filter struct {
+		UserID      string `json:"user_id"`
+		ServiceName string `json:"service_name"`
+	} `json:"filter"`
+	Changes struct {
+		ServiceName *string              `json:"service_name"`
+		Price       *uint                `json:"price"`
+		StartDate   *monthyear.MonthYear `json:"start_date"`
+		EndDate     *monthyear.MonthYear `json:"end_date"`
+	} `json:"changes"`
+}
+
+// @Summary	Массовое создание подписок в одной транзакции
+// @Accept		json
+// @Produce	json
+// @Param		Authorization	header		string						false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string						false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		dry_run			query		bool						false	"Только проверить, ничего не сохраняя"	default(false)
+// @Param		subscriptions	body		swagger.BulkCreateExample	true	"Список подписок"
+// @Success	200				{object}	swagger.BulkResultResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	422				{object}	swagger.BulkResultResponse
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/bulk/create [post]
+func BulkCreateSubscriptions(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	dryRun := c.Query("dry_run") == "true"
+
+	var req struct {
+		Subscriptions []model.Subscription `json:"subscriptions"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn("JSON bind error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
+		return
+	}
+	if len(req.Subscriptions) == 0 || len(req.Subscriptions) > BulkMaxItems {
+		log.Warn("invalid item count", "count", len(req.Subscriptions))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "number of subscriptions must be between 1 and 1000"})
+		return
+	}
+
+	log.Info("creating subscriptions", "count", len(req.Subscriptions), "dry_run", dryRun)
+
+	rows := make([]importRow, 0, len(req.Subscriptions))
+	rowErrors := []importRowError{}
+	for i, sub := range req.Subscriptions {
+		sub.ID = 0
+		if err := validateSubscription(sub); err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: i, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, importRow{line: i, sub: sub})
+	}
+
+	// A dry run only reports; creation repeats the check under the per-user
+	// locks of the transaction that inserts.
+	if StrictDuplicates && dryRun {
+		var err error
+		rows, rowErrors, err = rejectOverlappingRows(repository.DB.WithContext(ctx), rows, rowErrors, false)
+		if err != nil {
+			log.Error("DB overlap check error", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check overlapping records in db"})
+			return
+		}
+	}
+
+	var subs []model.Subscription
+	if !dryRun && len(rowErrors) == 0 {
+		err := repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if StrictDuplicates {
+				var err error
+				rows, rowErrors, err = rejectOverlappingRows(tx, rows, rowErrors, true)
+				if err != nil || len(rowErrors) > 0 {
+					return err
+				}
+			}
+
+			subs = make([]model.Subscription, 0, len(rows))
+			for _, row := range rows {
+				subs = append(subs, row.sub)
+			}
+			if err := tx.Create(&subs).Error; err != nil {
+				return err
+			}
+			return repository.AddToOutbox(tx, event.SubscriptionCreated, subs...)
+		})
+		if err != nil {
+			log.Error("DB transaction error", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create records in db"})
+			return
+		}
+	}
+
+	results := make([]bulkItemResult, len(req.Subscriptions))
+	for _, rowErr := range rowErrors {
+		results[rowErr.Row] = bulkItemResult{Index: rowErr.Row, Status: "invalid", Error: rowErr.Error}
+	}
+	for _, row := range rows {
+		results[row.line] = bulkItemResult{Index: row.line, Status: "valid"}
+	}
+
+	if dryRun || len(rowErrors) > 0 {
+		status := http.StatusOK
+		if !dryRun {
+			status = http.StatusUnprocessableEntity
+		}
+		log.Info("validated items", "valid", len(rows), "total", len(req.Subscriptions))
+		c.JSON(status, gin.H{"dry_run": dryRun, "count": len(rows), "results": results})
+		return
+	}
+
+	for i, sub := range subs {
+		results[rows[i].line] = bulkItemResult{Index: rows[i].line, ID: sub.ID, Status: "created"}
+	}
+
+	log.Info("created subscriptions", "count", len(subs))
+	c.JSON(http.StatusOK, gin.H{"dry_run": false, "count": len(subs), "results": results})
+}
+
+// @Summary	Массовое обновление подписок по фильтру (service_name / user_id), не более 1000 за запрос
+// @Accept		json
+// @Produce	json
+// @Param		Authorization	header		string						false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string						false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		dry_run			query		bool						false	"Только посчитать подходящие подписки"	default(false)
+// @Param		update			body		swagger.BulkUpdateExample	true	"Фильтр и изменения"
+// @Success	200				{object}	swagger.BulkResultResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	409				{object}	swagger.ErrorResponse409
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/bulk/update [post]
+func BulkUpdateSubscriptions(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	dryRun := c.Query("dry_run") == "true"
+
+	var req bulkUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn("JSON bind error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
+		return
+	}
+	if req.Filter.UserID == "" && req.Filter.ServiceName == "" {
+		log.Warn("empty filter")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "filter by user_id or service_name is required"})
+		return
+	}
+	if req.Filter.UserID != "" {
+		if _, err := uuid.Parse(req.Filter.UserID); err != nil {
+			log.Warn("invalid user_id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+	}
+
+	changes, err := bulkChanges(req)
+	if err != nil {
+		log.Warn("invalid changes", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Info("updating subscriptions", "user_id", req.Filter.UserID, "service_name", req.Filter.ServiceName, "dry_run", dryRun)
+
+	// Rows are validated as they will look after the update, so that e.g. an
+	// end_date alone is checked against each stored start_date.
+	var (
+		updated     []model.Subscription
+		tooMany     bool
+		invalid     error
+		overlapping *model.Subscription
+	)
+	err = repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.Subscription{})
+		if req.Filter.UserID != "" {
+			query = query.Where("user_id = ?", req.Filter.UserID)
+		}
+		if req.Filter.ServiceName != "" {
+			query = query.Where("service_name = ?", req.Filter.ServiceName)
+		}
+
+		if !dryRun {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		var matched []model.Subscription
+		if err := query.Order("id").Limit(BulkMaxItems + 1).Find(&matched).Error; err != nil {
+			return err
+		}
+		if tooMany = len(matched) > BulkMaxItems; tooMany || len(matched) == 0 {
+			return nil
+		}
+
+		updated = make([]model.Subscription, 0, len(matched))
+		for _, sub := range matched {
+			sub = applyBulkChanges(sub, req)
+			if err := validateSubscription(sub); err != nil {
+				invalid = fmt.Errorf("subscription %d: %w", sub.ID, err)
+				return nil
+			}
+			updated = append(updated, sub)
+		}
+
+		if StrictDuplicates {
+			var err error
+			if overlapping, err = findBulkOverlap(tx, updated, !dryRun); err != nil || overlapping != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(updated))
+		for _, sub := range updated {
+			ids = append(ids, sub.ID)
+		}
+		if err := tx.Model(&model.Subscription{}).Where("id IN ?", ids).Updates(changes).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", ids).Order("id").Find(&updated).Error; err != nil {
+			return err
+		}
+		return repository.AddToOutbox(tx, event.SubscriptionUpdated, updated...)
+	})
+	if err != nil {
+		log.Error("DB transaction error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update records in db"})
+		return
+	}
+	if tooMany {
+		log.Warn("filter matches too many subscriptions", "limit", BulkMaxItems)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "filter matches more than 1000 subscriptions"})
+		return
+	}
+	if invalid != nil {
+		log.Warn("invalid changes", "error", invalid)
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid.Error()})
+		return
+	}
+	if overlapping != nil {
+		log.Warn("overlaps with subscription", "id", overlapping.ID)
+		c.JSON(http.StatusConflict, gin.H{"error": "overlapping active subscription exists", "id": overlapping.ID})
+		return
+	}
+
+	status := "updated"
+	if dryRun {
+		status = "matched"
+	}
+	results := make([]bulkItemResult, 0, len(updated))
+	for i, sub := range updated {
+		results = append(results, bulkItemResult{Index: i, ID: sub.ID, Status: status})
+	}
+
+	log.Info("subscriptions "+status, "count", len(results))
+	c.JSON(http.StatusOK, gin.H{"dry_run": dryRun, "count": len(results), "results": results})
+}
+
+// @Summary	Массовое удаление подписок по списку ID
+// @Accept		json
+// @Produce	json
+// @Param		Authorization	header		string						false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string						false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		dry_run			query		bool						false	"Только посчитать существующие подписки"	default(false)
+// @Param		ids				body		swagger.BulkDeleteExample	true	"Список ID"
+// @Success	200				{object}	swagger.BulkResultResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/bulk/delete [post]
+func BulkDeleteSubscriptions(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	dryRun := c.Query("dry_run") == "true"
+
+	var req struct {
+		IDs []uint `json:"ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn("JSON bind error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
+		return
+	}
+	if len(req.IDs) == 0 || len(req.IDs) > BulkMaxItems {
+		log.Warn("invalid item count", "count", len(req.IDs))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "number of ids must be between 1 and 1000"})
+		return
+	}
+
+	log.Info("deleting subscriptions", "count", len(req.IDs), "dry_run", dryRun)
+
+	found := map[uint]bool{}
+	var existing []model.Subscription
+	err := repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", req.IDs).
+			Find(&existing).
+			Error
+		if err != nil {
+			return err
+		}
+		for _, sub := range existing {
+			found[sub.ID] = true
+		}
+
+		if dryRun || len(existing) == 0 {
+			return nil
+		}
+		if err := tx.Delete(&model.Subscription{}, "id IN ?", req.IDs).Error; err != nil {
+			return err
+		}
+		return repository.AddToOutbox(tx, event.SubscriptionDeleted, existing...)
+	})
+	if err != nil {
+		log.Error("DB transaction error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete records from db"})
+		return
+	}
+
+	status := "deleted"
+	if dryRun {
+		status = "matched"
+	}
+	results := make([]bulkItemResult, 0, len(req.IDs))
+	for i, id := range req.IDs {
+		if found[id] {
+			results = append(results, bulkItemResult{Index: i, ID: id, Status: status})
+		} else {
+			results = append(results, bulkItemResult{Index: i, ID: id, Status: "not_found"})
+		}
+	}
+
+	log.Info("subscriptions "+status, "count", len(found), "requested", len(req.IDs))
+	c.JSON(http.StatusOK, gin.H{"dry_run": dryRun, "count": len(found), "results": results})
+}
+
+func applyBulkChanges(sub model.Subscription, req bulkUpdateRequest) model.Subscription {
+	if req.Changes.ServiceName != nil {
+		sub.ServiceName = *req.Changes.ServiceName
+	}
+	if req.Changes.Price != nil {
+		sub.Price = *req.Changes.Price
+	}
+	if req.Changes.StartDate != nil {
+		sub.StartDate = *req.Changes.StartDate
+	}
+	if req.Changes.EndDate != nil {
+		endDate := *req.Changes.EndDate
+		sub.EndDate = &endDate
+	}
+	return sub
+}
+
+// findBulkOverlap checks the updated subscriptions against the other
+// subscriptions of their users and against each other. With lock set it
+// takes the per-user locks first, in a stable order.
+func findBulkOverlap(tx *gorm.DB, updated []model.Subscription, lock bool) (*model.Subscription, error) {
+	byID := make(map[uint]bool, len(updated))
+	userIDs := []uuid.UUID{}
+	for _, sub := range updated {
+		byID[sub.ID] = true
+		if !slices.Contains(userIDs, sub.UserID) {
+			userIDs = append(userIDs, sub.UserID)
+		}
+	}
+	if lock {
+		if err := lockUsers(tx, userIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	var existing []model.Subscription
+	if err := tx.Where("user_id IN ?", userIDs).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byUser := make(map[uuid.UUID][]model.Subscription, len(userIDs))
+	for _, sub := range existing {
+		if !byID[sub.ID] {
+			byUser[sub.UserID] = append(byUser[sub.UserID], sub)
+		}
+	}
+
+	for _, sub := range updated {
+		if overlapping := overlappingIn(sub, byUser[sub.UserID]); overlapping != nil {
+			return overlapping, nil
+		}
+		byUser[sub.UserID] = append(byUser[sub.UserID], sub)
+	}
+	return nil, nil
+}
+
+func bulkChanges(req bulkUpdateRequest) (map[string]interface{}, error) {
+	changes := map[string]interface{}{}
+	if req.Changes.ServiceName != nil {
+		if *req.Changes.ServiceName == "" {
+			return nil, errors.New("service_name must not be empty")
+		}
+		changes["service_name"] = *req.Changes.ServiceName
+	}
+	if req.Changes.Price != nil {
+		changes["price"] = *req.Changes.Price
+	}
+	if req.Changes.StartDate != nil {
+		changes["start_date"] = *req.Changes.StartDate
+	}
+	if req.Changes.EndDate != nil {
+		changes["end_date"] = *req.Changes.EndDate
+	}
+	if req.Changes.StartDate != nil && req.Changes.EndDate != nil &&
+		req.Changes.EndDate.Before(req.Changes.StartDate.Time) {
+		return nil, errors.New("end_date is before start_date")
+	}
+	if len(changes) == 0 {
+		return nil, errors.New("no changes provided")
+	}
+	return changes, nil
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	"subscription-aggregator/pkg/ical"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @Summary	Выпуск (или перевыпуск) секретного токена календаря пользователя
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		user_id			path		string	true	"ID пользователя"	default(11111111-1111-1111-1111-111111111111)
+// @Success	200				{object}	swagger.CalendarTokenResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/users/{user_id}/calendar-token [post]
+func IssueCalendarToken(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		log.Warn("invalid user_id", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+	if !authorizeUser(c, userID) {
+		return
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Error("token generation error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+	token := hex.EncodeToString(secret)
+
+	log.Info("issuing calendar token", "user_id", userID)
+
+	err = repository.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "updated_at"}),
+	}).Create(&model.CalendarToken{UserID: userID, TokenHash: hashCalendarToken(token)}).Error
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save token in db"})
+		return
+	}
+
+	log.Info("issued calendar token", "user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"token": token,
+		"url":   fmt.Sprintf("/users/%s/calendar.ics?token=%s", userID, token),
+	})
+}
+
+// @Summary	iCalendar-лента предстоящих списаний по активным подпискам пользователя
+// @Produce	text/calendar
+// @Param		user_id	path		string	true	"ID пользователя"	default(11111111-1111-1111-1111-111111111111)
+// @Param		token	query		string	true	"Секретный токен календаря"
+// @Success	200		{string}	string
+// @Failure	400		{object}	swagger.ErrorResponse400
+// @Failure	404		{object}	swagger.ErrorResponse404
+// @Failure	429		{object}	swagger.ErrorResponse429
+// @Failure	500		{object}	swagger.ErrorResponse500
+// @Router		/users/{user_id}/calendar.ics [get]
+func GetCalendarFeed(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		log.Warn("invalid user_id", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	// The feed is unauthenticated, so the token alone decides whose data (and
+	// which tenant's) is served.
+	var calendarToken model.CalendarToken
+	err = repository.DB.WithContext(ctx).First(&calendarToken, "token_hash = ?", hashCalendarToken(c.Query("token"))).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to find record in db"})
+		return
+	}
+	if err == gorm.ErrRecordNotFound || calendarToken.UserID != userID {
+		log.Warn("invalid token", "user_id", userID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+		return
+	}
+	ctx = auth.WithPrincipal(ctx, auth.Principal{UserID: userID, TenantID: calendarToken.TenantID, TenantBound: true})
+
+	var subs []model.Subscription
+	err = repository.DB.WithContext(ctx).
+		Where("user_id = ? AND (end_date IS NULL OR end_date >= ?)", userID, monthyear.Current()).
+		Order("start_date, id").
+		Find(&subs).
+		Error
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
+		return
+	}
+
+	cal := ical.Calendar{
+		ProdID: "-//subscription-aggregator//calendar//RU",
+		Name:   "Subscriptions",
+		Events: make([]ical.Event, 0, len(subs)),
+	}
+	for _, sub := range subs {
+		charge := sub.Price * uint(billingCycleMonths(sub))
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("subscription-%d@subscription-aggregator", sub.ID),
+			Summary:     fmt.Sprintf("%s: %d", sub.ServiceName, charge),
+			Description: fmt.Sprintf("Service: %s\nPrice: %d\nBilling cycle: %s", sub.ServiceName, charge, sub.BillingCycle),
+			Start:       sub.StartDate.Time,
+			RRule:       billingRRule(sub),
+		})
+	}
+
+	log.Info("serving calendar", "events", len(cal.Events), "user_id", userID)
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="calendar.ics"`)
+	c.Status(http.StatusOK)
+	if _, err := cal.WriteTo(c.Writer); err != nil {
+		log.Error("write error", "error", err)
+	}
+}
+
+// billingRRule repeats the charge every billing cycle on the day of the
+// start date, up to and including the end month.
+func billingRRule(sub model.Subscription) string {
+	months := billingCycleMonths(sub)
+
+	rrule := fmt.Sprintf("FREQ=MONTHLY;INTERVAL=%d", months)
+	if months%12 == 0 {
+		rrule = fmt.Sprintf("FREQ=YEARLY;INTERVAL=%d", months/12)
+	}
+	rrule += fmt.Sprintf(";BYMONTHDAY=%d", sub.StartDate.Day())
+	if sub.EndDate != nil {
+		rrule += ";UNTIL=" + sub.EndDate.Format("20060102")
+	}
+	return rrule
+}
+
+func billingCycleMonths(sub model.Subscription) int {
+	if months := model.BillingCycleMonths[sub.BillingCycle]; months > 0 {
+		return months
+	}
+	return 1
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
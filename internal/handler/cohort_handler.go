@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/gin-gonic/gin"
+)
+
+var cohortOffsets = []int{1, 3, 6, 12}
+
+type cohortRow struct {
+	Month     monthyear.MonthYear `json:"month"`
+	Size      int                 `json:"size"`
+	Retention []*float64          `json:"retention"`
+}
+
+// @Summary	Когортный отчёт удержания: доля подписок каждого месяца старта, активных через 1, 3, 6 и 12 месяцев
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		period_start	query		string	true	"Первый месяц когорт в формате MM-YYYY"		default(01-2025)
+// @Param		period_end		query		string	true	"Последний месяц когорт в формате MM-YYYY"	default(08-2025)
+// @Param		service_name	query		string	false	"Название сервиса"
+// @Success	200				{object}	swagger.CohortReportResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/analytics/cohorts [get]
+func GetCohortRetention(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	from, to, ok := bindPeriod(c)
+	if !ok {
+		return
+	}
+	serviceName := c.Query("service_name")
+
+	retention, err := repository.GetCohortRetention(ctx, from, to, serviceName, cohortOffsets)
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cohorts"})
+		return
+	}
+
+	currentMonth := monthyear.Current()
+	cohorts := []cohortRow{}
+	for _, r := range retention {
+		if len(cohorts) == 0 || !cohorts[len(cohorts)-1].Month.Equal(r.Month.Time) {
+			cohorts = append(cohorts, cohortRow{
+				Month:     r.Month,
+				Size:      r.Size,
+				Retention: make([]*float64, len(cohortOffsets)),
+			})
+		}
+
+		if r.Month.AddMonths(r.OffsetMonths).After(currentMonth.Time) {
+			continue
+		}
+		for i, offset := range cohortOffsets {
+			if offset == r.OffsetMonths {
+				share := float64(r.Retained) / float64(r.Size)
+				cohorts[len(cohorts)-1].Retention[i] = &share
+			}
+		}
+	}
+
+	log.Info("built cohorts", "count", len(cohorts))
+	c.JSON(http.StatusOK, gin.H{
+		"service_name": serviceName,
+		"offsets":      cohortOffsets,
+		"cohorts":      cohorts,
+	})
+}
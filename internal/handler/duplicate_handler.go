@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const duplicatePriceTolerance = 0.2
+
+var StrictDuplicates bool
+
+type duplicatePair struct {
+	Service   string             `json:"service"`
+	Original  model.Subscription `json:"original"`
+	Duplicate model.Subscription `json:"duplicate"`
+}
+
+// @Summary	Поиск вероятных дубликатов подписок пользователя (один сервис, пересекающиеся месяцы, близкая цена)
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		user_id			query		string	true	"ID пользователя"	default(11111111-1111-1111-1111-111111111111)
+// @Success	200				{array}		swagger.DuplicateResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/duplicates [get]
+func ListDuplicateSubscriptions(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		log.Warn("invalid user_id", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+	if !authorizeUser(c, userID) {
+		return
+	}
+
+	log.Info("searching duplicates", "user_id", userID)
+
+	var subs []model.Subscription
+	err = repository.DB.WithContext(ctx).Where("user_id = ?", userID).Order("start_date, id").Find(&subs).Error
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
+		return
+	}
+
+	pairs := []duplicatePair{}
+	for i := range subs {
+		for j := i + 1; j < len(subs); j++ {
+			if isLikelyDuplicate(subs[i], subs[j]) {
+				pairs = append(pairs, duplicatePair{
+					Service:   canonicalServiceName(subs[i].ServiceName),
+					Original:  subs[i],
+					Duplicate: subs[j],
+				})
+			}
+		}
+	}
+
+	log.Info("found duplicate pairs", "count", len(pairs))
+	c.JSON(http.StatusOK, pairs)
+}
+
+// findOverlappingSubscription must run in tx after
+// repository.LockUserSubscriptions for sub.UserID, otherwise a concurrent
+// request may insert an overlapping subscription right after the check.
+func findOverlappingSubscription(tx *gorm.DB, sub model.Subscription) (*model.Subscription, error) {
+	var subs []model.Subscription
+	err := tx.Where("user_id = ? AND id <> ?", sub.UserID, sub.ID).Find(&subs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return overlappingIn(sub, subs), nil
+}
+
+// lockUsers takes the per-user subscription locks in a stable order, so that
+// two requests touching the same users cannot deadlock.
+func lockUsers(tx *gorm.DB, userIDs []uuid.UUID) error {
+	userIDs = slices.Clone(userIDs)
+	slices.SortFunc(userIDs, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	for _, userID := range userIDs {
+		if err := repository.LockUserSubscriptions(tx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func overlappingIn(sub model.Subscription, candidates []model.Subscription) *model.Subscription {
+	for i := range candidates {
+		if sameService(sub, candidates[i]) && overlaps(sub, candidates[i]) {
+			return &candidates[i]
+		}
+	}
+	return nil
+}
+
+func isLikelyDuplicate(a, b model.Subscription) bool {
+	return sameService(a, b) && overlaps(a, b) && similarPrice(a.Price, b.Price)
+}
+
+func sameService(a, b model.Subscription) bool {
+	return canonicalServiceName(a.ServiceName) == canonicalServiceName(b.ServiceName)
+}
+
+func overlaps(a, b model.Subscription) bool {
+	if a.EndDate != nil && a.EndDate.Before(b.StartDate.Time) {
+		return false
+	}
+	if b.EndDate != nil && b.EndDate.Before(a.StartDate.Time) {
+		return false
+	}
+	return true
+}
+
+func similarPrice(a, b uint) bool {
+	hi, lo := a, b
+	if lo > hi {
+		hi, lo = lo, hi
+	}
+	return float64(hi-lo) <= float64(hi)*duplicatePriceTolerance
+}
+
+func canonicalServiceName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
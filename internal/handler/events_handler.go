@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/repository"
+	"subscription-aggregator/internal/stream"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const eventReplayBatch = 500
+
+// EventStream feeds GET /events; it is set at startup.
+var EventStream *stream.Hub
+
+// @Summary	Поток изменений подписок (Server-Sent Events); переподключение продолжает поток с заголовка Last-Event-ID
+// @Produce	text/event-stream
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		Last-Event-ID	header		int		false	"ID последнего полученного события"
+// @Param		user_id			query		string	false	"ID пользователя (для администратора - все пользователи, если не указан)"	default(11111111-1111-1111-1111-111111111111)
+// @Success	200				{string}	string	"События: id - ID для Last-Event-ID, event - тип, data - JSON события"
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	503				{object}	swagger.ErrorResponse503
+// @Router		/events [get]
+func StreamEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var userID uuid.UUID
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			log.Warn("invalid user_id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		userID = id
+	}
+	defaultUserID(c, &userID)
+	if userID != uuid.Nil && !authorizeUser(c, userID) {
+		return
+	}
+
+	var lastID uint64
+	resume := false
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			log.Warn("invalid Last-Event-ID", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+			return
+		}
+		lastID, resume = id, true
+	}
+
+	filter := stream.Filter{TenantID: repository.TenantID(ctx), UserID: userID}
+	sub, backlog, replayTo, err := EventStream.Subscribe(filter, uint(lastID), resume)
+	if err != nil {
+		log.Warn("event stream unavailable", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "event stream unavailable"})
+		return
+	}
+	defer EventStream.Unsubscribe(sub)
+
+	// The stream outlives the server's write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	log.Info("event stream opened", "user_id", userID, "last_event_id", c.GetHeader("Last-Event-ID"))
+
+	send := func(e stream.Entry) bool {
+		_, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+		return err == nil
+	}
+
+	// Events older than the hub's buffer are read back from the outbox.
+	for after := uint(lastID); replayTo > after; {
+		rows, err := repository.OutboxEventsAfter(ctx, after, eventReplayBatch)
+		if err != nil {
+			log.Error("DB error", "error", err)
+			return
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			if row.ID > replayTo {
+				break
+			}
+			after = row.ID
+			entry, err := stream.NewEntry(row)
+			if err != nil || !filter.Match(entry) {
+				continue
+			}
+			if !send(entry) {
+				return
+			}
+		}
+		if after < rows[len(rows)-1].ID {
+			break
+		}
+		c.Writer.Flush()
+	}
+	for _, e := range backlog {
+		if !send(e) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(EventStream.Heartbeat())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("event stream closed by client")
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				log.Info("event stream closed by server")
+				return
+			}
+			if !send(e) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
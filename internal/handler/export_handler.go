@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+	"subscription-aggregator/pkg/xlsx"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatXLSX   = "xlsx"
+)
+
+// exportFlushRows is how many rows are written between flushes to the client,
+// so that a long export shows progress without a syscall per row.
+const exportFlushRows = 500
+
+var exportContentTypes = map[string]string{
+	ExportFormatCSV:    "text/csv; charset=utf-8",
+	ExportFormatNDJSON: "application/x-ndjson",
+	ExportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+var exportHeader = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "category", "billing_cycle"}
+
+// @Summary	Выгрузка подписок в CSV, NDJSON или XLSX (фильтры как у /list)
+// @Produce	text/csv
+// @Produce	application/x-ndjson
+// @Produce	application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		format			query		string	false	"Формат выгрузки"	Enums(csv, ndjson, xlsx)	default(csv)
+// @Param		user_id			query		string	false	"ID пользователя"	default(11111111-1111-1111-1111-111111111111)
+// @Param		service_name	query		string	false	"Название сервиса"	default(Netflix)
+// @Success	200				{file}		file
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/export [get]
+func ExportSubscriptions(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	format := c.DefaultQuery("format", ExportFormatCSV)
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		log.Warn("invalid format", "value", format)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format"})
+		return
+	}
+
+	userID := c.Query("user_id")
+	serviceName := c.Query("service_name")
+
+	log.Info("exporting", "format", format, "user_id", userID, "service_name", serviceName)
+
+	query := repository.FilterSubscriptions(ctx, userID, serviceName).Model(&model.Subscription{}).Order("id")
+	rows, err := query.Rows()
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
+		return
+	}
+	defer rows.Close()
+
+	// Large exports take longer than the server's write timeout; the export
+	// ends on its own once the rows run out or the client goes away.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn("failed to clear write deadline", "error", err)
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="subscriptions.`+format+`"`)
+	c.Status(http.StatusOK)
+
+	count := 0
+	next := func(sub *model.Subscription) (bool, error) {
+		if !rows.Next() {
+			return false, rows.Err()
+		}
+		*sub = model.Subscription{}
+		count++
+		return true, repository.DB.WithContext(ctx).ScanRows(rows, sub)
+	}
+
+	switch format {
+	case ExportFormatCSV:
+		err = exportCSV(c, next)
+	case ExportFormatNDJSON:
+		err = exportNDJSON(c, next)
+	case ExportFormatXLSX:
+		err = exportXLSX(c, next, userID, serviceName)
+	}
+	if err != nil {
+		log.Error("export aborted", "rows", count, "error", err)
+		c.Abort()
+		return
+	}
+
+	log.Info("exported subscriptions", "count", count)
+}
+
+func exportCSV(c *gin.Context, next func(*model.Subscription) (bool, error)) error {
+	w := csv.NewWriter(c.Writer)
+	if err := w.Write(exportHeader); err != nil {
+		return err
+	}
+
+	var sub model.Subscription
+	for rows := 1; ; rows++ {
+		ok, err := next(&sub)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if err := w.Write(exportRecord(sub)); err != nil {
+			return err
+		}
+		if rows%exportFlushRows == 0 {
+			if w.Flush(); w.Error() != nil {
+				return w.Error()
+			}
+			c.Writer.Flush()
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func exportNDJSON(c *gin.Context, next func(*model.Subscription) (bool, error)) error {
+	enc := json.NewEncoder(c.Writer)
+
+	var sub model.Subscription
+	for rows := 1; ; rows++ {
+		ok, err := next(&sub)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if err := enc.Encode(sub); err != nil {
+			return err
+		}
+		if rows%exportFlushRows == 0 {
+			c.Writer.Flush()
+		}
+	}
+}
+
+func exportXLSX(
+	c *gin.Context,
+	next func(*model.Subscription) (bool, error),
+	userID, serviceName string,
+) error {
+	w := xlsx.NewWriter(c.Writer)
+	if err := w.AddSheet("Subscriptions"); err != nil {
+		return err
+	}
+	if err := w.WriteRow(toCells(exportHeader)...); err != nil {
+		return err
+	}
+
+	var sub model.Subscription
+	for {
+		ok, err := next(&sub)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		// Numbers stay numeric cells; everything else matches the CSV.
+		cells := toCells(exportRecord(sub))
+		cells[0], cells[2] = sub.ID, sub.Price
+		if err := w.WriteRow(cells...); err != nil {
+			return err
+		}
+	}
+
+	totals, err := repository.GetMonthlyTotals(c.Request.Context(), userID, serviceName, monthyear.Current())
+	if err != nil {
+		return err
+	}
+
+	if err := w.AddSheet("Monthly totals"); err != nil {
+		return err
+	}
+	if err := w.WriteRow("month", "subscriptions", "total"); err != nil {
+		return err
+	}
+	for _, total := range totals {
+		if err := w.WriteRow(total.Month.String(), total.Subscriptions, total.Total); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+func exportRecord(sub model.Subscription) []string {
+	endDate := ""
+	if sub.EndDate != nil {
+		endDate = sub.EndDate.String()
+	}
+	return []string{
+		strconv.FormatUint(uint64(sub.ID), 10),
+		sub.ServiceName,
+		strconv.FormatUint(uint64(sub.Price), 10),
+		sub.UserID.String(),
+		sub.StartDate.String(),
+		endDate,
+		sub.Category,
+		sub.BillingCycle,
+	}
+}
+
+func toCells(values []string) []interface{} {
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		cells[i] = v
+	}
+	return cells
+}
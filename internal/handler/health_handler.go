@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type healthCheck struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// @Summary	Проверка, что процесс жив
+// @Produce	json
+// @Success	200	{object}	swagger.HealthResponse
+// @Router		/healthz [get]
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"checks": gin.H{"process": healthCheck{Status: "ok"}},
+	})
+}
+
+// @Summary	Проверка готовности: доступность БД и применённые миграции
+// @Produce	json
+// @Success	200	{object}	swagger.HealthResponse
+// @Failure	503	{object}	swagger.HealthResponse
+// @Router		/readyz [get]
+func Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+	log := logger.FromContext(ctx)
+
+	checks := map[string]healthCheck{
+		"database":   runHealthCheck(ctx, repository.Ping),
+		"migrations": runHealthCheck(ctx, repository.CheckMigrations),
+	}
+
+	status := http.StatusOK
+	overall := "ok"
+	for name, check := range checks {
+		if check.Status != "ok" {
+			log.Warn("readiness check failed", "check", name, "error", check.Error)
+			status = http.StatusServiceUnavailable
+			overall = "unavailable"
+		}
+	}
+
+	c.JSON(status, gin.H{"status": overall, "checks": checks})
+}
+
+func runHealthCheck(ctx context.Context, check func(context.Context) error) healthCheck {
+	start := time.Now()
+	err := check(ctx)
+	result := healthCheck{
+		Status:    "ok",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,369 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ImportModeTransaction = "transaction"
+	ImportModeBatch       = "batch"
+
+	importBatchSize = 500
+	// ImportMaxBytes caps the size of the whole multipart upload.
+	ImportMaxBytes = 10 << 20
+)
+
+var importRequiredColumns = []string{"service_name", "price", "user_id", "start_date"}
+
+type importRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type importRow struct {
+	line int
+	sub  model.Subscription
+}
+
+// @Summary	Импорт подписок из CSV (service_name, price, user_id, start_date, end_date, category, billing_cycle) с пробным режимом и отчётом об ошибках
+// @Accept		multipart/form-data
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		file			formData	file	true	"CSV файл с заголовком"
+// @Param		dry_run			query		bool	false	"Только проверить строки, ничего не сохраняя"						default(false)
+// @Param		mode			query		string	false	"transaction - всё или ничего, batch - сохранить валидные строки"	Enums(transaction, batch)	default(transaction)
+// @Success	200				{object}	swagger.ImportReportResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	413				{object}	swagger.ErrorResponse413
+// @Failure	422				{object}	swagger.ImportReportResponse
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/import [post]
+func ImportSubscriptions(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	dryRun := c.Query("dry_run") == "true"
+	mode := c.DefaultQuery("mode", ImportModeTransaction)
+	if mode != ImportModeTransaction && mode != ImportModeBatch {
+		log.Warn("invalid mode", "value", mode)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mode"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ImportMaxBytes)
+	fileHeader, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		log.Warn("upload too large", "limit", tooLarge.Limit)
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+		return
+	}
+	if err != nil {
+		log.Warn("form file error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Warn("open file error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to open file"})
+		return
+	}
+	defer file.Close()
+
+	log.Info("importing", "file", fileHeader.Filename, "size", fileHeader.Size, "mode", mode, "dry_run", dryRun)
+
+	principal, _ := auth.FromContext(ctx)
+	rows, rowErrors, total, err := parseImportCSV(file, principal)
+	if err != nil {
+		log.Warn("CSV error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A dry run only reports; imports repeat the check under the per-user
+	// locks of the transaction that inserts.
+	if StrictDuplicates && dryRun {
+		rows, rowErrors, err = rejectOverlappingRows(repository.DB.WithContext(ctx), rows, rowErrors, false)
+		if err != nil {
+			log.Error("DB overlap check error", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check overlapping records in db"})
+			return
+		}
+	}
+
+	report := gin.H{
+		"dry_run":  dryRun,
+		"mode":     mode,
+		"total":    total,
+		"valid":    len(rows),
+		"inserted": 0,
+		"errors":   rowErrors,
+	}
+
+	if dryRun {
+		log.Info("dry run completed", "valid", len(rows), "total", total)
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	if mode == ImportModeTransaction {
+		if len(rowErrors) > 0 {
+			log.Warn("rejected import with invalid rows", "invalid", len(rowErrors))
+			c.JSON(http.StatusUnprocessableEntity, report)
+			return
+		}
+
+		var subs []model.Subscription
+		err = repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if StrictDuplicates {
+				var err error
+				rows, rowErrors, err = rejectOverlappingRows(tx, rows, rowErrors, true)
+				if err != nil || len(rowErrors) > 0 {
+					return err
+				}
+			}
+
+			subs = make([]model.Subscription, 0, len(rows))
+			for _, row := range rows {
+				subs = append(subs, row.sub)
+			}
+			if err := tx.CreateInBatches(&subs, importBatchSize).Error; err != nil {
+				return err
+			}
+			return repository.AddToOutbox(tx, event.SubscriptionCreated, subs...)
+		})
+		if err != nil {
+			log.Error("DB transaction error", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create records in db"})
+			return
+		}
+		if len(rowErrors) > 0 {
+			report["valid"] = len(rows)
+			report["errors"] = rowErrors
+			log.Warn("rejected import with overlapping rows", "invalid", len(rowErrors))
+			c.JSON(http.StatusUnprocessableEntity, report)
+			return
+		}
+
+		report["inserted"] = len(subs)
+		log.Info("inserted rows in transaction", "count", len(subs))
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	inserted, valid := 0, len(rows)
+	for start := 0; start < len(rows); start += importBatchSize {
+		batch := rows[start:min(start+importBatchSize, len(rows))]
+
+		var (
+			subs       []model.Subscription
+			overlapped []importRowError
+		)
+		err := repository.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			accepted := batch
+			if StrictDuplicates {
+				var err error
+				if accepted, overlapped, err = rejectOverlappingRows(tx, batch, nil, true); err != nil {
+					return err
+				}
+			}
+
+			subs = make([]model.Subscription, 0, len(accepted))
+			for _, row := range accepted {
+				subs = append(subs, row.sub)
+			}
+			if len(subs) == 0 {
+				return nil
+			}
+			if err := tx.Create(&subs).Error; err != nil {
+				return err
+			}
+			return repository.AddToOutbox(tx, event.SubscriptionCreated, subs...)
+		})
+		if err != nil {
+			log.Error("DB batch error", "error", err)
+			for _, row := range batch {
+				rowErrors = append(rowErrors, importRowError{Row: row.line, Error: "failed to create record in db"})
+			}
+			continue
+		}
+		rowErrors = append(rowErrors, overlapped...)
+		valid -= len(overlapped)
+		inserted += len(subs)
+	}
+
+	report["inserted"] = inserted
+	report["valid"] = valid
+	report["errors"] = rowErrors
+
+	log.Info("inserted rows in batches", "inserted", inserted, "total", total)
+	c.JSON(http.StatusOK, report)
+}
+
+func parseImportCSV(r io.Reader, principal auth.Principal) ([]importRow, []importRowError, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, 0, errors.New("failed to read CSV header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, 0, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	rows := []importRow{}
+	rowErrors := []importRowError{}
+	total := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		total++
+		// Quoted fields may span lines, so rows are reported by the line
+		// they start on rather than by their ordinal.
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rowErrors = append(rowErrors, importRowError{Row: parseErr.StartLine, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		sub, err := parseImportRecord(record, columns)
+		if err == nil {
+			err = validateSubscription(sub)
+		}
+		if err == nil && !principal.CanAccess(sub.UserID) {
+			err = errors.New("user_id belongs to another user")
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, importRow{line: line, sub: sub})
+	}
+
+	return rows, rowErrors, total, nil
+}
+
+func parseImportRecord(record []string, columns map[string]int) (model.Subscription, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var sub model.Subscription
+	sub.ServiceName = field("service_name")
+	sub.Category = field("category")
+	sub.BillingCycle = field("billing_cycle")
+
+	price, err := strconv.ParseUint(field("price"), 10, 0)
+	if err != nil {
+		return sub, errors.New("invalid price")
+	}
+	sub.Price = uint(price)
+
+	sub.UserID, err = uuid.Parse(field("user_id"))
+	if err != nil {
+		return sub, errors.New("invalid user_id")
+	}
+
+	sub.StartDate, err = monthyear.Parse(field("start_date"))
+	if err != nil {
+		return sub, errors.New("invalid start_date")
+	}
+
+	if raw := field("end_date"); raw != "" {
+		endDate, err := monthyear.Parse(raw)
+		if err != nil {
+			return sub, errors.New("invalid end_date")
+		}
+		sub.EndDate = &endDate
+	}
+
+	return sub, nil
+}
+
+// rejectOverlappingRows moves rows that overlap a stored subscription or an
+// earlier row to rowErrors. With lock set it takes the per-user locks first,
+// so the check holds until tx commits; callers that insert must pass the
+// transaction they insert in.
+func rejectOverlappingRows(tx *gorm.DB, rows []importRow, rowErrors []importRowError, lock bool) ([]importRow, []importRowError, error) {
+	userIDs := make([]uuid.UUID, 0, len(rows))
+	seen := make(map[uuid.UUID]bool, len(rows))
+	for _, row := range rows {
+		if !seen[row.sub.UserID] {
+			seen[row.sub.UserID] = true
+			userIDs = append(userIDs, row.sub.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return rows, rowErrors, nil
+	}
+	if lock {
+		if err := lockUsers(tx, userIDs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var existing []model.Subscription
+	if err := tx.Where("user_id IN ?", userIDs).Find(&existing).Error; err != nil {
+		return nil, nil, err
+	}
+
+	byUser := make(map[uuid.UUID][]model.Subscription, len(userIDs))
+	for _, sub := range existing {
+		byUser[sub.UserID] = append(byUser[sub.UserID], sub)
+	}
+
+	accepted := make([]importRow, 0, len(rows))
+	for _, row := range rows {
+		if overlapping := overlappingIn(row.sub, byUser[row.sub.UserID]); overlapping != nil {
+			message := "overlapping active subscription exists"
+			if overlapping.ID != 0 {
+				message = fmt.Sprintf("%s (id=%d)", message, overlapping.ID)
+			}
+			rowErrors = append(rowErrors, importRowError{Row: row.line, Error: message})
+			continue
+		}
+		byUser[row.sub.UserID] = append(byUser[row.sub.UserID], row.sub)
+		accepted = append(accepted, row)
+	}
+
+	return accepted, rowErrors, nil
+}
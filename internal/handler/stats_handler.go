@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type monthOverMonth struct {
+	PreviousSpend int      `json:"previous_spend"`
+	CurrentSpend  int      `json:"current_spend"`
+	Change        int      `json:"change"`
+	ChangePercent *float64 `json:"change_percent"`
+}
+
+// @Summary	Статистика трат пользователя: активные подписки, средние траты в месяц, самый дорогой сервис и т.д.
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT)"
+// @Param		user_id			query		string	true	"ID пользователя"	default(11111111-1111-1111-1111-111111111111)
+// @Success	200				{object}	swagger.UserStatsResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/stats [get]
+func GetUserStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		log.Warn("invalid user_id", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+	if !authorizeUser(c, userID) {
+		return
+	}
+
+	currentMonth := monthyear.Current()
+	previousMonth := currentMonth.AddMonths(-1)
+
+	log.Info("calculating stats", "user_id", userID, "month", currentMonth)
+
+	summary, err := repository.GetUserStatsSummary(ctx, userID, currentMonth)
+	if err != nil {
+		log.Error("DB summary error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get stats"})
+		return
+	}
+
+	mostExpensive, err := repository.GetMostExpensiveActiveSubscription(ctx, userID, currentMonth)
+	if err != nil {
+		log.Error("DB most expensive error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get stats"})
+		return
+	}
+
+	longestRunning, err := repository.GetLongestRunningSubscription(ctx, userID, currentMonth)
+	if err != nil {
+		log.Error("DB longest running error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get stats"})
+		return
+	}
+
+	spend, err := repository.GetMonthlySpend(ctx, userID, repository.SpendFilter{}, previousMonth, currentMonth)
+	if err != nil || len(spend) != 2 {
+		log.Error("DB monthly spend error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get stats"})
+		return
+	}
+
+	mom := monthOverMonth{
+		PreviousSpend: spend[0].Total,
+		CurrentSpend:  spend[1].Total,
+		Change:        spend[1].Total - spend[0].Total,
+	}
+	if spend[0].Total != 0 {
+		percent := float64(mom.Change) * 100 / float64(spend[0].Total)
+		mom.ChangePercent = &percent
+	}
+
+	tenant, err := repository.CurrentTenant(ctx)
+	if err != nil {
+		log.Error("DB tenant error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get stats"})
+		return
+	}
+
+	averageMonthlySpend := 0.0
+	if summary.FirstMonth != nil {
+		months := summary.FirstMonth.MonthsUntil(currentMonth) + 1
+		averageMonthlySpend = float64(summary.LifetimeSpend) / float64(months)
+	}
+
+	log.Info("stats calculated", "user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":               userID,
+		"month":                 currentMonth,
+		"active_count":          summary.ActiveCount,
+		"average_monthly_spend": averageMonthlySpend,
+		"most_expensive":        mostExpensive,
+		"month_over_month":      mom,
+		"longest_running":       longestRunning,
+		"lifetime_spend":        summary.LifetimeSpend,
+		"currency":              tenant.DefaultCurrency,
+	})
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type tenantRequest struct {
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	DefaultCurrency string    `json:"default_currency"`
+}
+
+// @Summary	Настройки текущего тенанта
+// @Produce	json
+// @Param		Authorization	header		string	true	"Bearer-токен (JWT) или API-ключ"
+// @Param		X-Tenant-ID		header		string	false	"ID тенанта (только для администраторов платформы)"
+// @Success	200				{object}	swagger.TenantResponse
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/tenant/settings [get]
+func GetTenantSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	tenant, err := repository.CurrentTenant(ctx)
+	if err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to find record in db"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// @Summary	Изменение настроек текущего тенанта: название и валюта по умолчанию
+// @Accept		json
+// @Produce	json
+// @Param		Authorization	header		string							false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string							false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		X-Tenant-ID		header		string							false	"ID тенанта (только для администраторов платформы)"
+// @Param		settings		body		swagger.TenantSettingsExample	true	"Настройки тенанта"
+// @Success	200				{object}	swagger.MessageResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/tenant/settings [put]
+func UpdateTenantSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var req tenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn("JSON bind error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
+		return
+	}
+	if err := validateTenantRequest(&req); err != nil {
+		log.Warn("invalid tenant settings", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID := repository.TenantID(ctx)
+	log.Info("updating tenant settings", "name", req.Name, "default_currency", req.DefaultCurrency)
+
+	err := repository.DB.WithContext(ctx).Model(&model.Tenant{}).
+		Where("id = ?", tenantID).
+		Updates(map[string]interface{}{"name": req.Name, "default_currency": req.DefaultCurrency}).
+		Error
+	if err != nil {
+		log.Error("DB update error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update record in db"})
+		return
+	}
+	repository.ForgetTenant(tenantID)
+
+	log.Info("updated tenant settings")
+	c.JSON(http.StatusOK, gin.H{"message": "updated"})
+}
+
+// @Summary	Создание тенанта (только для администраторов платформы)
+// @Accept		json
+// @Produce	json
+// @Param		Authorization	header		string					false	"Bearer-токен (JWT) с ролью JWT_PLATFORM_ROLE без tenant_id"
+// @Param		X-Admin-Token	header		string					false	"Токен администратора платформы (вместо JWT)"
+// @Param		tenant			body		swagger.TenantExample	true	"Данные тенанта; id генерируется, если не указан"
+// @Success	200				{object}	swagger.TenantResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/tenants/create [post]
+func CreateTenant(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var req tenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn("JSON bind error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
+		return
+	}
+	if req.DefaultCurrency == "" {
+		req.DefaultCurrency = "RUB"
+	}
+	if err := validateTenantRequest(&req); err != nil {
+		log.Warn("invalid tenant", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+
+	tenant := model.Tenant{ID: req.ID, Name: req.Name, DefaultCurrency: req.DefaultCurrency}
+
+	log.Info("creating tenant", "id", tenant.ID, "name", tenant.Name)
+
+	if err := repository.DB.WithContext(ctx).Create(&tenant).Error; err != nil {
+		log.Error("DB create error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create record in db"})
+		return
+	}
+
+	log.Info("created tenant", "id", tenant.ID)
+	c.JSON(http.StatusOK, tenant)
+}
+
+// @Summary	Список тенантов (только для администраторов платформы)
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью JWT_PLATFORM_ROLE без tenant_id"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора платформы (вместо JWT)"
+// @Success	200				{array}		swagger.TenantResponse
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/tenants/list [get]
+func ListTenants(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var tenants []model.Tenant
+	if err := repository.DB.WithContext(ctx).Order("created_at, id").Find(&tenants).Error; err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
+		return
+	}
+
+	log.Info("found tenants", "count", len(tenants))
+	c.JSON(http.StatusOK, tenants)
+}
+
+func validateTenantRequest(req *tenantRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.DefaultCurrency = strings.ToUpper(strings.TrimSpace(req.DefaultCurrency))
+
+	switch {
+	case req.Name == "":
+		return errors.New("name is required")
+	case !currencyCode.MatchString(req.DefaultCurrency):
+		return errors.New("default_currency must be a three-letter ISO 4217 code")
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	"subscription-aggregator/internal/webhook"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookDeliveriesLimit    = 50
+	webhookDeliveriesMaxLimit = 500
+	webhookSecretMinLength    = 16
+)
+
+// AllowPrivateWebhookTargets accepts endpoints on loopback and private
+// networks; see config.WebhooksConfig.AllowPrivateTargets.
+var AllowPrivateWebhookTargets bool
+
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// @Summary	Регистрация вебхука (секрет возвращается один раз)
+// @Accept		json
+// @Produce	json
+// @Param		Authorization	header		string					false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string					false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		webhook			body		swagger.WebhookExample	true	"URL, типы событий и (необязательно) секрет"
+// @Success	200				{object}	swagger.WebhookCreatedResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/webhooks/create [post]
+func CreateWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn("JSON bind error", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to bind JSON"})
+		return
+	}
+	if err := validateWebhookRequest(ctx, &req); err != nil {
+		log.Warn("invalid webhook request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Secret == "" {
+		secret, err := webhook.GenerateSecret()
+		if err != nil {
+			log.Error("secret generation error", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret"})
+			return
+		}
+		req.Secret = secret
+	}
+
+	endpoint := model.WebhookEndpoint{URL: req.URL, Secret: req.Secret, Events: req.Events}
+
+	log.Info("creating webhook", "url", endpoint.URL, "events", req.Events)
+
+	if err := repository.DB.WithContext(ctx).Create(&endpoint).Error; err != nil {
+		log.Error("DB create error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create record in db"})
+		return
+	}
+
+	log.Info("created webhook", "id", endpoint.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "created", "id": endpoint.ID, "secret": endpoint.Secret})
+}
+
+// @Summary	Список вебхуков тенанта (без секретов)
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Success	200				{array}		swagger.WebhookResponse
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/webhooks/list [get]
+func ListWebhooks(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var endpoints []model.WebhookEndpoint
+	if err := repository.DB.WithContext(ctx).Order("id").Find(&endpoints).Error; err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
+		return
+	}
+
+	log.Info("found webhooks", "count", len(endpoints))
+	c.JSON(http.StatusOK, endpoints)
+}
+
+// @Summary	Отключение вебхука по ID (журнал доставок сохраняется)
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		id				path		int		true	"ID вебхука"	default(1)
+// @Success	200				{object}	swagger.MessageResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/webhooks/delete/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Warn("invalid id param", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	log.Info("disabling webhook", "id", id)
+
+	result := repository.DB.WithContext(ctx).Model(&model.WebhookEndpoint{}).
+		Where("id = ? AND disabled_at IS NULL", id).
+		Update("disabled_at", time.Now())
+	if result.Error != nil {
+		log.Error("DB update error", "error", result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update record in db"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Warn("no active webhook found", "id", id)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+		return
+	}
+
+	log.Info("disabled webhook", "id", id)
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+// @Summary	Журнал доставок вебхуков, новые сначала
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		webhook_id		query		int		false	"ID вебхука"
+// @Param		status			query		string	false	"Статус: pending, succeeded или failed"
+// @Param		limit			query		int		false	"Количество записей (до 500)"	default(50)
+// @Success	200				{array}		swagger.WebhookDeliveryResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/webhooks/deliveries [get]
+func ListWebhookDeliveries(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	query := repository.DB.WithContext(ctx).Order("id DESC")
+
+	if raw := c.Query("webhook_id"); raw != "" {
+		webhookID, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Warn("invalid webhook_id", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook_id"})
+			return
+		}
+		query = query.Where("webhook_id = ?", webhookID)
+	}
+
+	if status := c.Query("status"); status != "" {
+		if status != model.DeliveryPending && status != model.DeliverySucceeded && status != model.DeliveryFailed {
+			log.Warn("invalid status", "status", status)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, succeeded or failed"})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	limit := webhookDeliveriesLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > webhookDeliveriesMaxLimit {
+			log.Warn("invalid limit", "limit", raw)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	var deliveries []model.WebhookDelivery
+	if err := query.Limit(limit).Find(&deliveries).Error; err != nil {
+		log.Error("DB error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get records from db"})
+		return
+	}
+
+	log.Info("found webhook deliveries", "count", len(deliveries))
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// @Summary	Повторная отправка доставки вебхука по ID
+// @Produce	json
+// @Param		Authorization	header		string	false	"Bearer-токен (JWT) с ролью admin"
+// @Param		X-Admin-Token	header		string	false	"Токен администратора (вместо JWT с ролью admin)"
+// @Param		id				path		int		true	"ID доставки"	default(1)
+// @Success	200				{object}	swagger.WebhookDeliveryResponse
+// @Failure	400				{object}	swagger.ErrorResponse400
+// @Failure	401				{object}	swagger.ErrorResponse401
+// @Failure	403				{object}	swagger.ErrorResponse403
+// @Failure	404				{object}	swagger.ErrorResponse404
+// @Failure	429				{object}	swagger.ErrorResponse429
+// @Failure	500				{object}	swagger.ErrorResponse500
+// @Router		/webhooks/redeliver/{id} [post]
+func RedeliverWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		log.Warn("invalid id param", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	log.Info("redelivering webhook", "delivery_id", id)
+
+	delivery, err := repository.RedeliverWebhook(ctx, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Warn("record not found", "delivery_id", id)
+		c.JSON(http.StatusNotFound, gin.H{"error": "record not found in db"})
+		return
+	}
+	if err != nil {
+		log.Error("DB create error", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create record in db"})
+		return
+	}
+
+	log.Info("queued redelivery", "delivery_id", delivery.ID, "redelivery_of", id)
+	c.JSON(http.StatusOK, delivery)
+}
+
+func validateWebhookRequest(ctx context.Context, req *webhookRequest) error {
+	req.URL = strings.TrimSpace(req.URL)
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if err := webhook.CheckURL(ctx, req.URL, AllowPrivateWebhookTargets); err != nil {
+		if errors.Is(err, webhook.ErrForbiddenAddress) {
+			return errors.New("url must not point to a private, loopback or link-local address")
+		}
+		return errors.New("url host cannot be resolved")
+	}
+	if len(req.Events) == 0 {
+		return errors.New("at least one event type is required")
+	}
+	for _, eventType := range req.Events {
+		if !slices.Contains(event.Types, eventType) {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	slices.Sort(req.Events)
+	req.Events = slices.Compact(req.Events)
+
+	if req.Secret != "" && len(req.Secret) < webhookSecretMinLength {
+		return fmt.Errorf("secret must be at least %d characters", webhookSecretMinLength)
+	}
+	return nil
+}
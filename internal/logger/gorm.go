@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const slowQueryThreshold = 200 * time.Millisecond
+
+type GormLogger struct {
+	level gormlogger.LogLevel
+}
+
+func NewGormLogger() *GormLogger {
+	return &GormLogger{level: gormlogger.Warn}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return &GormLogger{level: level}
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	log := FromContext(ctx)
+	attrs := func() []any {
+		sql, rows := fc()
+		return []any{
+			"sql", sql,
+			"rows", rows,
+			"duration_ms", float64(elapsed.Microseconds()) / 1000,
+		}
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		log.ErrorContext(ctx, "query failed", append(attrs(), "error", err)...)
+	case elapsed > slowQueryThreshold && l.level >= gormlogger.Warn:
+		log.WarnContext(ctx, "slow query", attrs()...)
+	case log.Enabled(ctx, slog.LevelDebug):
+		log.DebugContext(ctx, "query", attrs()...)
+	}
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics are registered with the default Prometheus registry, which also
+// exposes the go_* and process_* collectors.
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	HTTPRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Total number of requests rejected by the rate limiter by route group.",
+	}, []string{"group"})
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_delivery_attempts_total",
+		Help: "Total number of webhook delivery attempts by outcome.",
+	}, []string{"outcome"})
+	OutboxPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_publish_attempts_total",
+		Help: "Total number of outbox event publish attempts by outcome.",
+	}, []string{"outcome"})
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Database query latency by operation and table.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation", "table"})
+	DBQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Total number of failed database queries by operation and table.",
+	}, []string{"operation", "table"})
+
+	// SubscriptionsActive and SubscriptionsTotal are refreshed periodically
+	// rather than on scrape, so that scrapes never hit the database.
+	SubscriptionsActive = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "subscriptions_active",
+		Help: "Number of subscriptions active in the current month.",
+	})
+	SubscriptionsTotal = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "subscriptions_total",
+		Help: "Number of stored subscriptions.",
+	})
+)
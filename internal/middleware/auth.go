@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+type APIKeyResolver func(ctx context.Context, key string) (auth.Principal, error)
+
+// Authenticate resolves the caller from an `Authorization: Bearer <JWT>` or
+// `Authorization: ApiKey <key>` header, or from the static admin token kept
+// for operational scripts, and stores the principal in the request context.
+// Anonymous requests get 401; API keys without the scope the HTTP method
+// needs (read for GET, write otherwise) get 403.
+func Authenticate(verifier *auth.Verifier, apiKeys APIKeyResolver, adminToken string) gin.HandlerFunc {
+	if !verifier.Enabled() {
+		slog.Warn("no JWT secret or JWKS configured, bearer tokens will be rejected")
+	}
+	if adminToken == "" {
+		slog.Warn("ADMIN_TOKEN not set, the X-Admin-Token header is disabled")
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		log := logger.FromContext(ctx)
+
+		var principal auth.Principal
+		switch {
+		case strings.HasPrefix(c.GetHeader("Authorization"), "Bearer "):
+			p, err := verifier.Verify(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+			if err != nil {
+				log.Warn("rejected bearer token", "error", err)
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+				return
+			}
+			principal = p
+
+		case strings.HasPrefix(c.GetHeader("Authorization"), "ApiKey "):
+			p, err := apiKeys(ctx, strings.TrimPrefix(c.GetHeader("Authorization"), "ApiKey "))
+			if err != nil {
+				log.Warn("rejected api key", "error", err)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+				return
+			}
+			principal = p
+
+		case c.GetHeader(AdminTokenHeader) != "":
+			provided := c.GetHeader(AdminTokenHeader)
+			if adminToken == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) != 1 {
+				log.Warn("rejected admin token")
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+				return
+			}
+			principal = auth.Principal{Admin: true, PlatformAdmin: true, Method: auth.MethodAdminToken}
+
+		default:
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+
+		if scope := requiredScope(c.Request.Method); !principal.HasScope(scope) {
+			log.Warn("api key lacks scope", "key_id", principal.KeyID, "scope", scope)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api key lacks " + scope + " scope"})
+			return
+		}
+
+		log = log.With("auth_method", principal.Method, "auth_user_id", principal.UserID, "auth_admin", principal.Admin)
+		if principal.KeyID != 0 {
+			log = log.With("api_key_id", principal.KeyID)
+		}
+		ctx = auth.WithPrincipal(logger.WithContext(ctx, log), principal)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// AdminOnly must run after Authenticate.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if !ok || !principal.Admin {
+			logger.FromContext(c.Request.Context()).Warn("rejected admin request")
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func requiredScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return auth.ScopeRead
+	default:
+		return auth.ScopeWrite
+	}
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/config"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	testSecret     = "0123456789abcdef0123456789abcdef"
+	testAdminToken = "admin-token"
+)
+
+var (
+	testUserID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	readKeyID  = uint(7)
+
+	defaultTenant = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	otherTenant   = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+)
+
+func hs256(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	input := encode(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func userClaims(extra map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub": testUserID.String(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return claims
+}
+
+func resolveTestKey(_ context.Context, key string) (auth.Principal, error) {
+	switch key {
+	case "read-key":
+		return auth.Principal{UserID: testUserID, Method: auth.MethodAPIKey, KeyID: readKeyID, Scopes: []string{auth.ScopeRead}}, nil
+	case "write-key":
+		return auth.Principal{Method: auth.MethodAPIKey, KeyID: 8, Scopes: []string{auth.ScopeRead, auth.ScopeWrite}}, nil
+	case "tenant-admin-key":
+		return auth.Principal{Method: auth.MethodAPIKey, KeyID: 9, Scopes: []string{auth.ScopeAdmin}, TenantID: otherTenant, TenantBound: true}, nil
+	default:
+		return auth.Principal{}, errors.New("unknown api key")
+	}
+}
+
+func newTestVerifier(t *testing.T) *auth.Verifier {
+	t.Helper()
+	verifier, err := auth.NewVerifier(config.AuthConfig{JWTSecret: testSecret, AdminRole: "admin", PlatformRole: "platform-admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verifier
+}
+
+// newTestRouter mounts a handler that echoes the resolved principal behind
+// Authenticate, plus an /admin route guarded by AdminOnly.
+func newTestRouter(t *testing.T, adminToken string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	verifier := newTestVerifier(t)
+	echo := func(c *gin.Context) {
+		principal, _ := auth.FromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"user_id": principal.UserID, "admin": principal.Admin, "method": principal.Method})
+	}
+
+	r := gin.New()
+	api := r.Group("/", Authenticate(verifier, resolveTestKey, adminToken))
+	api.GET("/items", echo)
+	api.POST("/items", echo)
+	api.GET("/admin", AdminOnly(), echo)
+	return r
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		method     string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantMethod string
+		wantAdmin  bool
+	}{
+		{"no credentials", testAdminToken, http.MethodGet, "/items", nil, http.StatusUnauthorized, "", false},
+		{"bearer token", testAdminToken, http.MethodGet, "/items", map[string]string{"Authorization": "Bearer " + hs256(t, userClaims(nil))}, http.StatusOK, auth.MethodJWT, false},
+		{"bearer token writes", testAdminToken, http.MethodPost, "/items", map[string]string{"Authorization": "Bearer " + hs256(t, userClaims(nil))}, http.StatusOK, auth.MethodJWT, false},
+		{"expired bearer token", testAdminToken, http.MethodGet, "/items", map[string]string{"Authorization": "Bearer " + hs256(t, userClaims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}))}, http.StatusUnauthorized, "", false},
+		{"garbage bearer token", testAdminToken, http.MethodGet, "/items", map[string]string{"Authorization": "Bearer garbage"}, http.StatusUnauthorized, "", false},
+		{"read key reads", testAdminToken, http.MethodGet, "/items", map[string]string{"Authorization": "ApiKey read-key"}, http.StatusOK, auth.MethodAPIKey, false},
+		{"read key writes", testAdminToken, http.MethodPost, "/items", map[string]string{"Authorization": "ApiKey read-key"}, http.StatusForbidden, "", false},
+		{"write key writes", testAdminToken, http.MethodPost, "/items", map[string]string{"Authorization": "ApiKey write-key"}, http.StatusOK, auth.MethodAPIKey, false},
+		{"unknown key", testAdminToken, http.MethodGet, "/items", map[string]string{"Authorization": "ApiKey nope"}, http.StatusUnauthorized, "", false},
+		{"admin token", testAdminToken, http.MethodGet, "/items", map[string]string{AdminTokenHeader: testAdminToken}, http.StatusOK, auth.MethodAdminToken, true},
+		{"wrong admin token", testAdminToken, http.MethodGet, "/items", map[string]string{AdminTokenHeader: "guess"}, http.StatusUnauthorized, "", false},
+		{"admin token when unset", "", http.MethodGet, "/items", map[string]string{AdminTokenHeader: "anything"}, http.StatusUnauthorized, "", false},
+		{"admin route as user", testAdminToken, http.MethodGet, "/admin", map[string]string{"Authorization": "Bearer " + hs256(t, userClaims(nil))}, http.StatusForbidden, "", false},
+		{"admin route as admin JWT", testAdminToken, http.MethodGet, "/admin", map[string]string{"Authorization": "Bearer " + hs256(t, userClaims(map[string]interface{}{"role": "admin"}))}, http.StatusOK, auth.MethodJWT, true},
+		{"admin route with unbound key", testAdminToken, http.MethodGet, "/admin", map[string]string{"Authorization": "ApiKey write-key"}, http.StatusForbidden, "", false},
+		{"admin route with admin token", testAdminToken, http.MethodGet, "/admin", map[string]string{AdminTokenHeader: testAdminToken}, http.StatusOK, auth.MethodAdminToken, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, tt.adminToken)
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got struct {
+				Admin  bool   `json:"admin"`
+				Method string `json:"method"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Method != tt.wantMethod || got.Admin != tt.wantAdmin {
+				t.Fatalf("principal = %+v, want method %q admin %v", got, tt.wantMethod, tt.wantAdmin)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"subscription-aggregator/internal/ratelimit"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordingStore lets every request through and remembers the bucket keys.
+type recordingStore struct {
+	keys []string
+}
+
+func (s *recordingStore) Take(_ context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	s.keys = append(s.keys, key)
+	return ratelimit.Decision{Allowed: true, Limit: limit.Burst, Remaining: limit.Burst}, nil
+}
+
+func TestIPRateLimitTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := ratelimit.Limit{Rate: 1, Burst: 10}
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		wantKey        string
+	}{
+		{"direct client", nil, "203.0.113.7:5000", "", "api:ip:203.0.113.7"},
+		{"forwarded header without trusted proxies", nil, "203.0.113.7:5000", "198.51.100.1", "api:ip:203.0.113.7"},
+		{"forwarded header from an untrusted peer", []string{"10.0.0.0/8"}, "203.0.113.7:5000", "198.51.100.1", "api:ip:203.0.113.7"},
+		{"forwarded header from a trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.2:5000", "198.51.100.1", "api:ip:198.51.100.1"},
+		{"trusted proxy given as an address", []string{"10.0.0.2"}, "10.0.0.2:5000", "198.51.100.1", "api:ip:198.51.100.1"},
+		{"spoofed hop before a trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.2:5000", "1.2.3.4, 198.51.100.1", "api:ip:198.51.100.1"},
+		{"chain of trusted proxies", []string{"10.0.0.0/8"}, "10.0.0.2:5000", "198.51.100.1, 10.0.0.3", "api:ip:198.51.100.1"},
+		{"trusted proxy without the header", []string{"10.0.0.0/8"}, "10.0.0.2:5000", "", "api:ip:10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &recordingStore{}
+			r := gin.New()
+			if err := r.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatal(err)
+			}
+			r.Use(IPRateLimit(store, "api", limit))
+			r.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			if len(store.keys) != 1 || store.keys[0] != tt.wantKey {
+				t.Errorf("bucket keys = %v, want [%s]", store.keys, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestIPRateLimitRejectsSpoofedClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := r.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	r.Use(IPRateLimit(ratelimit.NewMemoryStore(), "api", ratelimit.Limit{Rate: 0.001, Burst: 2}))
+	r.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Rotating X-Forwarded-For must not buy an untrusted client new buckets.
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		req.Header.Set("X-Forwarded-For", "198.51.100."+string(rune('1'+i)))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("request %d: status = %d, want %d", i, w.Code, want)
+		}
+		if want == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Error("429 without Retry-After")
+		}
+	}
+}
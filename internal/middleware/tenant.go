@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const TenantHeader = "X-Tenant-ID"
+
+type TenantChecker func(ctx context.Context, id uuid.UUID) (bool, error)
+
+// Tenant must run after Authenticate. It settles which tenant the request acts
+// in: the one named by the credential, or for credentials without a tenant the
+// default one. Only platform admins (the platform role or the admin token) may
+// pick another tenant with the X-Tenant-ID header; everyone else, including
+// tenant admins whose token lacks tenant_id, may only repeat their own.
+func Tenant(exists TenantChecker, defaultTenant uuid.UUID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		log := logger.FromContext(ctx)
+
+		principal, ok := auth.FromContext(ctx)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		if !principal.TenantBound {
+			principal.TenantID = defaultTenant
+		}
+
+		if raw := c.GetHeader(TenantHeader); raw != "" {
+			requested, err := uuid.Parse(raw)
+			if err != nil {
+				log.Warn("invalid tenant header", "value", raw)
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid " + TenantHeader + " header"})
+				return
+			}
+			if requested != principal.TenantID && !principal.Platform() {
+				log.Warn("rejected tenant switch", "tenant_id", principal.TenantID, "requested_tenant_id", requested)
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access to another tenant is forbidden"})
+				return
+			}
+			principal.TenantID = requested
+		}
+
+		found, err := exists(ctx, principal.TenantID)
+		if err != nil {
+			log.Error("tenant lookup error", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to get record from db"})
+			return
+		}
+		if !found {
+			log.Warn("unknown tenant", "tenant_id", principal.TenantID)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "unknown tenant"})
+			return
+		}
+
+		log = log.With("tenant_id", principal.TenantID)
+		ctx = auth.WithPrincipal(logger.WithContext(ctx, log), principal)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// PlatformAdminOnly must run after Authenticate. It admits only platform admins
+// whose credential is not tied to a tenant.
+func PlatformAdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.FromContext(c.Request.Context())
+		if !ok || !principal.Platform() {
+			logger.FromContext(c.Request.Context()).Warn("rejected platform admin request")
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "platform admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"subscription-aggregator/internal/auth"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func tenantExists(_ context.Context, id uuid.UUID) (bool, error) {
+	return id == defaultTenant || id == otherTenant, nil
+}
+
+func newTenantRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	echo := func(c *gin.Context) {
+		principal, _ := auth.FromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"tenant_id": principal.TenantID})
+	}
+
+	r := gin.New()
+	api := r.Group("/", Authenticate(newTestVerifier(t), resolveTestKey, testAdminToken), Tenant(tenantExists, defaultTenant))
+	api.GET("/items", echo)
+	api.GET("/tenants", PlatformAdminOnly(), echo)
+	return r
+}
+
+func TestTenant(t *testing.T) {
+	user := "Bearer " + hs256(t, userClaims(nil))
+	tenantAdmin := "Bearer " + hs256(t, userClaims(map[string]interface{}{"role": "admin"}))
+	boundUser := "Bearer " + hs256(t, userClaims(map[string]interface{}{"tenant_id": otherTenant.String()}))
+	platform := "Bearer " + hs256(t, userClaims(map[string]interface{}{"role": "platform-admin"}))
+	boundPlatform := "Bearer " + hs256(t, userClaims(map[string]interface{}{"role": "platform-admin", "tenant_id": otherTenant.String()}))
+	unknownTenant := "Bearer " + hs256(t, userClaims(map[string]interface{}{"tenant_id": uuid.NewString()}))
+
+	tests := []struct {
+		name       string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantTenant uuid.UUID
+	}{
+		{"user gets the default tenant", "/items", map[string]string{"Authorization": user}, http.StatusOK, defaultTenant},
+		{"user repeats own tenant", "/items", map[string]string{"Authorization": user, TenantHeader: defaultTenant.String()}, http.StatusOK, defaultTenant},
+		{"user switches tenant", "/items", map[string]string{"Authorization": user, TenantHeader: otherTenant.String()}, http.StatusForbidden, uuid.Nil},
+		{"admin without tenant_id stays in the default tenant", "/items", map[string]string{"Authorization": tenantAdmin}, http.StatusOK, defaultTenant},
+		{"admin without tenant_id switches tenant", "/items", map[string]string{"Authorization": tenantAdmin, TenantHeader: otherTenant.String()}, http.StatusForbidden, uuid.Nil},
+		{"bound user", "/items", map[string]string{"Authorization": boundUser}, http.StatusOK, otherTenant},
+		{"bound user switches to the default tenant", "/items", map[string]string{"Authorization": boundUser, TenantHeader: defaultTenant.String()}, http.StatusForbidden, uuid.Nil},
+		{"bound API key", "/items", map[string]string{"Authorization": "ApiKey tenant-admin-key"}, http.StatusOK, otherTenant},
+		{"bound API key switches tenant", "/items", map[string]string{"Authorization": "ApiKey tenant-admin-key", TenantHeader: defaultTenant.String()}, http.StatusForbidden, uuid.Nil},
+		{"platform admin", "/items", map[string]string{"Authorization": platform}, http.StatusOK, defaultTenant},
+		{"platform admin switches tenant", "/items", map[string]string{"Authorization": platform, TenantHeader: otherTenant.String()}, http.StatusOK, otherTenant},
+		{"bound platform admin switches tenant", "/items", map[string]string{"Authorization": boundPlatform, TenantHeader: defaultTenant.String()}, http.StatusForbidden, uuid.Nil},
+		{"admin token switches tenant", "/items", map[string]string{AdminTokenHeader: testAdminToken, TenantHeader: otherTenant.String()}, http.StatusOK, otherTenant},
+		{"invalid tenant header", "/items", map[string]string{AdminTokenHeader: testAdminToken, TenantHeader: "acme"}, http.StatusBadRequest, uuid.Nil},
+		{"unknown tenant", "/items", map[string]string{"Authorization": unknownTenant}, http.StatusForbidden, uuid.Nil},
+		{"platform route as tenant admin", "/tenants", map[string]string{"Authorization": tenantAdmin}, http.StatusForbidden, uuid.Nil},
+		{"platform route as tenant admin key", "/tenants", map[string]string{"Authorization": "ApiKey tenant-admin-key"}, http.StatusForbidden, uuid.Nil},
+		{"platform route as bound platform admin", "/tenants", map[string]string{"Authorization": boundPlatform}, http.StatusForbidden, uuid.Nil},
+		{"platform route as platform admin", "/tenants", map[string]string{"Authorization": platform}, http.StatusOK, defaultTenant},
+		{"platform route with admin token", "/tenants", map[string]string{AdminTokenHeader: testAdminToken}, http.StatusOK, defaultTenant},
+	}
+	r := newTenantRouter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got struct {
+				TenantID uuid.UUID `json:"tenant_id"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.TenantID != tt.wantTenant {
+				t.Fatalf("tenant = %s, want %s", got.TenantID, tt.wantTenant)
+			}
+		})
+	}
+}
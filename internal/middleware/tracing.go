@@ -0,0 +1,12 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Tracing starts a server span for every request, continuing the trace from
+// an incoming traceparent header. Spans are named "<method> <route>".
+func Tracing(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName)
+}
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    service_name  text   NOT NULL,
+    category      text,
+    price         bigint NOT NULL CONSTRAINT chk_subscriptions_price CHECK (price >= 0),
+    user_id       uuid   NOT NULL,
+    start_date    date   NOT NULL,
+    end_date      date,
+    billing_cycle text   NOT NULL DEFAULT 'monthly'
+        CONSTRAINT chk_subscriptions_billing_cycle CHECK (billing_cycle IN ('monthly', 'quarterly', 'yearly'))
+);
+
+-- Databases created by AutoMigrate before these fields existed lack the columns.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS end_date date;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS category text;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS billing_cycle text NOT NULL DEFAULT 'monthly';
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_deleted_at ON subscriptions (deleted_at);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS budgets (
+    id                bigserial PRIMARY KEY,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    deleted_at        timestamptz,
+    user_id           uuid   NOT NULL,
+    service_name      text,
+    category          text,
+    amount            bigint NOT NULL CONSTRAINT chk_budgets_amount CHECK (amount > 0),
+    -- No default: the handler applies one only when the field is omitted, so
+    -- that an explicit 0 is kept.
+    warning_threshold bigint NOT NULL CONSTRAINT chk_budgets_warning_threshold CHECK (warning_threshold <= 100)
+);
+
+-- Databases created by AutoMigrate before categories existed lack the column,
+-- and older ones still carry the threshold default.
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS category text;
+ALTER TABLE budgets ALTER COLUMN warning_threshold DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_budgets_deleted_at ON budgets (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets (user_id);
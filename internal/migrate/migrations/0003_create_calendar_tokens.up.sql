@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    user_id    uuid PRIMARY KEY,
+    token_hash text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_tokens_token_hash ON calendar_tokens (token_hash);
@@ -0,0 +1,5 @@
+-- NOT VALID skips the check of existing rows, so this neither fails on old
+-- bad data nor scans the table under an exclusive lock. The rows are fixed and
+-- the constraint validated in 0005.
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_subscriptions_end_date CHECK (end_date IS NULL OR end_date >= start_date) NOT VALID;
@@ -0,0 +1,2 @@
+-- The repaired end dates are not restored and a validated constraint cannot be
+-- marked NOT VALID again; 0004 down drops the constraint itself.
@@ -0,0 +1,7 @@
+-- Subscriptions saved before the check existed may end before they start.
+-- They are treated as lasting a single month.
+UPDATE subscriptions SET end_date = start_date WHERE end_date < start_date;
+
+-- Validation only takes a SHARE UPDATE EXCLUSIVE lock, so reads and writes
+-- continue while the table is scanned.
+ALTER TABLE subscriptions VALIDATE CONSTRAINT chk_subscriptions_end_date;
@@ -0,0 +1,6 @@
+-- migrate:no-transaction
+-- Serves /list filtered by user_id, optionally narrowed by service_name.
+-- If the build fails, drop the INVALID index before retrying.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_subscriptions_user_service
+    ON subscriptions (user_id, service_name)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+-- migrate:no-transaction
+-- Serves /list filtered by service_name only.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_subscriptions_service
+    ON subscriptions (service_name)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- migrate:no-transaction
+-- Serves /sum and budget evaluation: user_id and LOWER(service_name) equality
+-- with a range on start_date.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_subscriptions_user_lower_service_start
+    ON subscriptions (user_id, LOWER(service_name), start_date)
+    INCLUDE (price, end_date)
+    WHERE deleted_at IS NULL;
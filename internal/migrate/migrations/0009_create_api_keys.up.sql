@@ -0,0 +1,16 @@
+CREATE TABLE api_keys (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now(),
+    name         text   NOT NULL,
+    prefix       text   NOT NULL,
+    key_hash     text   NOT NULL,
+    scopes       text[] NOT NULL CONSTRAINT chk_api_keys_scopes
+        CHECK (cardinality(scopes) > 0 AND scopes <@ ARRAY['read', 'write', 'admin']),
+    user_id      uuid,
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    revoked_at   timestamptz
+);
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
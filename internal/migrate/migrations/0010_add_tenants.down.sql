@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_budgets_tenant_user;
+
+ALTER TABLE calendar_tokens
+    DROP CONSTRAINT calendar_tokens_pkey,
+    DROP COLUMN tenant_id,
+    ADD CONSTRAINT calendar_tokens_pkey PRIMARY KEY (user_id);
+
+ALTER TABLE api_keys DROP COLUMN tenant_id;
+ALTER TABLE budgets DROP COLUMN tenant_id;
+ALTER TABLE subscriptions DROP COLUMN tenant_id;
+
+DROP TABLE tenants;
@@ -0,0 +1,38 @@
+CREATE TABLE tenants (
+    id               uuid PRIMARY KEY,
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    updated_at       timestamptz NOT NULL DEFAULT now(),
+    name             text    NOT NULL,
+    default_currency char(3) NOT NULL DEFAULT 'RUB'
+        CONSTRAINT chk_tenants_default_currency CHECK (default_currency ~ '^[A-Z]{3}$')
+);
+
+-- Everything that existed before tenants belongs to the default tenant.
+INSERT INTO tenants (id, name) VALUES ('00000000-0000-0000-0000-000000000001', 'default');
+
+-- A constant default makes ADD COLUMN a metadata-only change; it is dropped
+-- right away so new rows must always name their tenant.
+ALTER TABLE subscriptions
+    ADD COLUMN tenant_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+        CONSTRAINT fk_subscriptions_tenant REFERENCES tenants (id);
+ALTER TABLE subscriptions ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE budgets
+    ADD COLUMN tenant_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+        CONSTRAINT fk_budgets_tenant REFERENCES tenants (id);
+ALTER TABLE budgets ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE api_keys
+    ADD COLUMN tenant_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+        CONSTRAINT fk_api_keys_tenant REFERENCES tenants (id);
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE calendar_tokens
+    ADD COLUMN tenant_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+        CONSTRAINT fk_calendar_tokens_tenant REFERENCES tenants (id);
+ALTER TABLE calendar_tokens
+    ALTER COLUMN tenant_id DROP DEFAULT,
+    DROP CONSTRAINT calendar_tokens_pkey,
+    ADD CONSTRAINT calendar_tokens_pkey PRIMARY KEY (tenant_id, user_id);
+
+CREATE INDEX idx_budgets_tenant_user ON budgets (tenant_id, user_id);
@@ -0,0 +1,5 @@
+-- migrate:no-transaction
+-- Serves tenant-wide admin queries (analytics, bulk operations).
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_subscriptions_tenant_service
+    ON subscriptions (tenant_id, LOWER(service_name))
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,44 @@
+CREATE TABLE webhook_endpoints (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    tenant_id   uuid   NOT NULL CONSTRAINT fk_webhook_endpoints_tenant REFERENCES tenants (id),
+    url         text   NOT NULL,
+    secret      text   NOT NULL,
+    events      text[] NOT NULL CONSTRAINT chk_webhook_endpoints_events
+        CHECK (cardinality(events) > 0 AND events <@ ARRAY[
+            'subscription.created', 'subscription.updated', 'subscription.deleted',
+            'subscription.restored', 'subscription.renewal_upcoming'
+        ]),
+    disabled_at timestamptz
+);
+
+CREATE INDEX idx_webhook_endpoints_tenant ON webhook_endpoints (tenant_id) WHERE disabled_at IS NULL;
+
+CREATE TABLE webhook_deliveries (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    updated_at      timestamptz NOT NULL DEFAULT now(),
+    tenant_id       uuid   NOT NULL CONSTRAINT fk_webhook_deliveries_tenant REFERENCES tenants (id),
+    webhook_id      bigint NOT NULL CONSTRAINT fk_webhook_deliveries_webhook REFERENCES webhook_endpoints (id),
+    event_id        uuid   NOT NULL,
+    event_type      text   NOT NULL,
+    payload         jsonb  NOT NULL,
+    status          text   NOT NULL CONSTRAINT chk_webhook_deliveries_status
+        CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts        int    NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    last_attempt_at timestamptz,
+    response_status int,
+    last_error      text   NOT NULL DEFAULT '',
+    delivered_at    timestamptz,
+    redelivery_of   bigint CONSTRAINT fk_webhook_deliveries_redelivery REFERENCES webhook_deliveries (id)
+);
+
+-- An event reaches an endpoint once; only manual redeliveries repeat it.
+CREATE UNIQUE INDEX idx_webhook_deliveries_event
+    ON webhook_deliveries (webhook_id, event_id) WHERE redelivery_of IS NULL;
+CREATE INDEX idx_webhook_deliveries_due
+    ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_log
+    ON webhook_deliveries (tenant_id, webhook_id, id DESC);
@@ -0,0 +1,22 @@
+CREATE TABLE outbox_events (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    tenant_id       uuid   NOT NULL CONSTRAINT fk_outbox_events_tenant REFERENCES tenants (id),
+    event_id        uuid   NOT NULL CONSTRAINT uni_outbox_events_event_id UNIQUE,
+    event_type      text   NOT NULL,
+    aggregate_id    bigint NOT NULL,
+    payload         jsonb  NOT NULL,
+    attempts        int    NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    last_error      text   NOT NULL DEFAULT '',
+    published_at    timestamptz
+);
+
+-- The relay only ever reads unpublished events: the head of each aggregate
+-- and the due ones in id order.
+CREATE INDEX idx_outbox_events_pending_aggregate
+    ON outbox_events (aggregate_id, id) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_events_pending_due
+    ON outbox_events (next_attempt_at, id) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_events_published
+    ON outbox_events (published_at) WHERE published_at IS NOT NULL;
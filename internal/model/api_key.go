@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is a credential for non-interactive clients. Only the SHA-256 hash of
+// the key is stored; Prefix keeps its first characters for identification.
+// A key without UserID acts on behalf of every user within its scopes.
+type APIKey struct {
+	ID         uint       `gorm:"primarykey"               json:"id"`
+	CreatedAt  time.Time  `                                json:"created_at"`
+	UpdatedAt  time.Time  `                                json:"-"`
+	TenantID   uuid.UUID  `gorm:"type:uuid;not null"       json:"-"`
+	Name       string     `gorm:"not null"                 json:"name"`
+	Prefix     string     `gorm:"not null"                 json:"prefix"`
+	KeyHash    string     `gorm:"not null;uniqueIndex"     json:"-"`
+	Scopes     TextArray  `gorm:"type:text[];not null"     json:"scopes"`
+	UserID     *uuid.UUID `gorm:"type:uuid"                json:"user_id,omitempty"`
+	ExpiresAt  *time.Time `                                json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `                                json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `                                json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the key is neither revoked nor expired at now.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Budget struct {
+	ID               uint           `gorm:"primarykey"                              json:"id"`
+	CreatedAt        time.Time      `                                               json:"-"`
+	UpdatedAt        time.Time      `                                               json:"-"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"                                   json:"-"`
+	TenantID         uuid.UUID      `gorm:"type:uuid;not null"                      json:"-"`
+	UserID           uuid.UUID      `gorm:"type:uuid;not null;index"                json:"user_id"`
+	ServiceName      string         `                                               json:"service_name,omitempty"`
+	Category         string         `                                               json:"category,omitempty"`
+	Amount           uint           `gorm:"not null;check:amount > 0"               json:"amount"`
+	WarningThreshold uint           `gorm:"not null;check:warning_threshold <= 100" json:"warning_threshold"`
+}
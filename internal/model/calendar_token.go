@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CalendarToken struct {
+	TenantID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
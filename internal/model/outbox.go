@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a domain event saved in the same transaction as the change
+// it describes. The relay publishes events of one aggregate in ID order and
+// sets PublishedAt once every publisher has accepted the event.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primarykey"          json:"id"`
+	CreatedAt     time.Time  `                           json:"created_at"`
+	TenantID      uuid.UUID  `gorm:"type:uuid;not null"  json:"-"`
+	EventID       uuid.UUID  `gorm:"type:uuid;not null"  json:"event_id"`
+	EventType     string     `gorm:"not null"            json:"event_type"`
+	AggregateID   uint       `gorm:"not null"            json:"aggregate_id"`
+	Payload       JSON       `gorm:"type:jsonb;not null" json:"payload"`
+	Attempts      int        `gorm:"not null"            json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null"            json:"next_attempt_at"`
+	LastError     string     `gorm:"not null;default:''" json:"last_error,omitempty"`
+	PublishedAt   *time.Time `                           json:"published_at,omitempty"`
+}
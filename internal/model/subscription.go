@@ -9,13 +9,32 @@ import (
 	"gorm.io/gorm"
 )
 
+// Billing cycles of a subscription. Price is always the monthly price; the
+// cycle only decides how often it is charged.
+const (
+	BillingMonthly   = "monthly"
+	BillingQuarterly = "quarterly"
+	BillingYearly    = "yearly"
+)
+
+// BillingCycleMonths is the number of months between charges of each cycle.
+var BillingCycleMonths = map[string]int{
+	BillingMonthly:   1,
+	BillingQuarterly: 3,
+	BillingYearly:    12,
+}
+
 type Subscription struct {
-	ID          uint                `gorm:"primarykey"                json:"id"`
-	CreatedAt   time.Time           `                                 json:"-"`
-	UpdatedAt   time.Time           `                                 json:"-"`
-	DeletedAt   gorm.DeletedAt      `gorm:"index"                     json:"-"`
-	ServiceName string              `gorm:"not null"                  json:"service_name"`
-	Price       uint                `gorm:"not null;check:price >= 0" json:"price"`
-	UserID      uuid.UUID           `gorm:"type:uuid;not null"        json:"user_id"`
-	StartDate   monthyear.MonthYear `gorm:"type:date;not null"        json:"start_date"`
+	ID           uint                 `gorm:"primarykey"                json:"id"`
+	CreatedAt    time.Time            `                                 json:"-"`
+	UpdatedAt    time.Time            `                                 json:"-"`
+	DeletedAt    gorm.DeletedAt       `gorm:"index"                     json:"-"`
+	TenantID     uuid.UUID            `gorm:"type:uuid;not null"        json:"-"`
+	ServiceName  string               `gorm:"not null"                  json:"service_name"`
+	Category     string               `                                 json:"category,omitempty"`
+	Price        uint                 `gorm:"not null;check:price >= 0" json:"price"`
+	UserID       uuid.UUID            `gorm:"type:uuid;not null"        json:"user_id"`
+	StartDate    monthyear.MonthYear  `gorm:"type:date;not null"        json:"start_date"`
+	EndDate      *monthyear.MonthYear `gorm:"type:date"                 json:"end_date,omitempty"`
+	BillingCycle string               `gorm:"not null;default:monthly"  json:"billing_cycle"`
 }
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenant is a partner company whose data is isolated from everyone else's.
+// Every tenant-owned table carries a tenant_id referencing it.
+type Tenant struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey"          json:"id"`
+	CreatedAt       time.Time `                                     json:"created_at"`
+	UpdatedAt       time.Time `                                     json:"-"`
+	Name            string    `gorm:"not null"                      json:"name"`
+	DefaultCurrency string    `gorm:"type:char(3);not null"         json:"default_currency"`
+}
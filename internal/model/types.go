@@ -0,0 +1,81 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// TextArray is stored as a Postgres text[] of plain identifiers (no commas,
+// quotes or braces), such as API key scopes or webhook event types.
+type TextArray []string
+
+func (a TextArray) Has(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (a TextArray) Value() (driver.Value, error) {
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+func (a *TextArray) Scan(value interface{}) error {
+	raw, err := scanText(value)
+	if err != nil {
+		return fmt.Errorf("cannot scan %T into TextArray", value)
+	}
+
+	raw = strings.Trim(raw, "{}")
+	if raw == "" {
+		*a = TextArray{}
+		return nil
+	}
+	*a = strings.Split(raw, ",")
+	return nil
+}
+
+// JSON is a jsonb column passed through verbatim, both to the database and in
+// API responses.
+type JSON []byte
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	raw, err := scanText(value)
+	if err != nil {
+		return fmt.Errorf("cannot scan %T into JSON", value)
+	}
+	*j = JSON(raw)
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func scanText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", value)
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEndpoint receives the tenant's events of the listed types. Secret
+// signs every payload; deleting an endpoint only disables it so that its
+// delivery log stays intact.
+type WebhookEndpoint struct {
+	ID         uint       `gorm:"primarykey"           json:"id"`
+	CreatedAt  time.Time  `                            json:"created_at"`
+	UpdatedAt  time.Time  `                            json:"-"`
+	TenantID   uuid.UUID  `gorm:"type:uuid;not null"   json:"-"`
+	URL        string     `gorm:"not null"             json:"url"`
+	Secret     string     `gorm:"not null"             json:"-"`
+	Events     TextArray  `gorm:"type:text[];not null" json:"events"`
+	DisabledAt *time.Time `                            json:"disabled_at,omitempty"`
+}
+
+// WebhookDelivery is one event queued for one endpoint together with the
+// outcome of its latest attempt. A manual redelivery is a new row pointing at
+// the original through RedeliveryOf.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primarykey"          json:"id"`
+	CreatedAt      time.Time  `                           json:"created_at"`
+	UpdatedAt      time.Time  `                           json:"updated_at"`
+	TenantID       uuid.UUID  `gorm:"type:uuid;not null"  json:"-"`
+	WebhookID      uint       `gorm:"not null"            json:"webhook_id"`
+	EventID        uuid.UUID  `gorm:"type:uuid;not null"  json:"event_id"`
+	EventType      string     `gorm:"not null"            json:"event_type"`
+	Payload        JSON       `gorm:"type:jsonb;not null" json:"payload"`
+	Status         string     `gorm:"not null"            json:"status"`
+	Attempts       int        `gorm:"not null"            json:"attempts"`
+	NextAttemptAt  *time.Time `                           json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `                           json:"last_attempt_at,omitempty"`
+	ResponseStatus *int       `                           json:"response_status,omitempty"`
+	LastError      string     `gorm:"not null;default:''" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `                           json:"delivered_at,omitempty"`
+	RedeliveryOf   *uint      `                           json:"redelivery_of,omitempty"`
+}
@@ -0,0 +1,255 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/repository"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher hands an event to a downstream system. Delivery is at least once:
+// an event is published again when the relay fails before recording success,
+// so consumers should deduplicate by event ID. Publishers holding connections
+// also implement io.Closer.
+type Publisher interface {
+	Publish(ctx context.Context, e event.Event) error
+}
+
+// NewPublisher builds the publishers named in the configuration.
+func NewPublisher(cfg config.OutboxConfig) (Publisher, error) {
+	var publishers Multi
+	for _, name := range cfg.Publishers {
+		switch name {
+		case "webhooks":
+			publishers = append(publishers, WebhookPublisher{})
+		case "log":
+			publishers = append(publishers, LogPublisher{})
+		case "http":
+			publishers = append(publishers, NewHTTPPublisher(cfg.HTTPURL, cfg.HTTPTimeout))
+		case "nats":
+			p, err := NewNATSPublisher(cfg.NATSURL, cfg.NATSSubject)
+			if err != nil {
+				_ = publishers.Close()
+				return nil, err
+			}
+			publishers = append(publishers, p)
+		case "kafka":
+			publishers = append(publishers, NewKafkaPublisher(cfg.KafkaBrokers, cfg.KafkaTopic))
+		default:
+			_ = publishers.Close()
+			return nil, fmt.Errorf("unknown publisher %q", name)
+		}
+	}
+	if len(publishers) == 1 {
+		return publishers[0], nil
+	}
+	return publishers, nil
+}
+
+// Multi publishes to every publisher in turn. An event counts as published
+// only when all of them succeed; on retry the ones that succeeded get it
+// again.
+type Multi []Publisher
+
+func (m Multi) Publish(ctx context.Context, e event.Event) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every publisher that holds a connection.
+func (m Multi) Close() error {
+	var errs []error
+	for _, p := range m {
+		if closer, ok := p.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WebhookPublisher queues deliveries to the tenant's webhook endpoints. It
+// is idempotent: an event already queued for an endpoint is skipped.
+type WebhookPublisher struct{}
+
+func (WebhookPublisher) Publish(ctx context.Context, e event.Event) error {
+	return repository.EnqueueWebhookEvents(ctx, e)
+}
+
+// LogPublisher writes events to the application log.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(_ context.Context, e event.Event) error {
+	slog.Info("event published",
+		"event_id", e.ID,
+		"type", e.Type,
+		"tenant_id", e.TenantID,
+		"data", e.Data,
+	)
+	return nil
+}
+
+// HTTPPublisher POSTs every event as JSON to a fixed URL; any response other
+// than 2xx is an error.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// wireEvent adds the tenant, which the public event representation omits. It
+// is the body sent by the http, nats and kafka publishers.
+type wireEvent struct {
+	event.Event
+	TenantID string `json:"tenant_id"`
+}
+
+func marshalWireEvent(e event.Event) ([]byte, error) {
+	return json.Marshal(wireEvent{Event: e, TenantID: e.TenantID.String()})
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, e event.Event) error {
+	body, err := marshalWireEvent(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", e.ID.String())
+	req.Header.Set("X-Event-Type", e.Type)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("http publisher: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NATSPublisher publishes every event to JetStream on <subject>.<event type>,
+// e.g. aggregator.events.subscription.created. A stream must capture these
+// subjects. The event ID is sent as Nats-Msg-Id, so JetStream drops the
+// duplicates a relay retry produces within the stream's duplicate window, and
+// an event counts as published only once the stream acknowledges it.
+type NATSPublisher struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	subject string
+}
+
+// NewNATSPublisher connects in the background: an unreachable server does not
+// stop the service, publishing just fails and is retried until it is back.
+func NewNATSPublisher(url, subject string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("subscription-aggregator"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("nats publisher: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats publisher: %w", err)
+	}
+	return &NATSPublisher{conn: conn, js: js, subject: subject}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, e event.Event) error {
+	body, err := marshalWireEvent(e)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.subject + "." + e.Type)
+	msg.Data = body
+	msg.Header.Set("Content-Type", "application/json")
+	msg.Header.Set("X-Event-Type", e.Type)
+	msg.Header.Set("X-Tenant-ID", e.TenantID.String())
+
+	if _, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(e.ID.String())); err != nil {
+		return fmt.Errorf("nats publisher: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection. Nothing is pending by then: every publish
+// waited for its acknowledgement.
+func (p *NATSPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
+
+// KafkaPublisher writes every event to one topic, keyed by the subscription
+// ID so that the events of a subscription land in one partition in order. A
+// write succeeds once all in-sync replicas have the message.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// The relay writes one event at a time per subscription, so waiting
+		// to fill a batch would only add latency.
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, e event.Event) error {
+	body, err := marshalWireEvent(e)
+	if err != nil {
+		return err
+	}
+
+	err = p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strconv.FormatUint(uint64(e.AggregateID), 10)),
+		Value: body,
+		Headers: []kafka.Header{
+			{Key: "X-Event-ID", Value: []byte(e.ID.String())},
+			{Key: "X-Event-Type", Value: []byte(e.Type)},
+			{Key: "X-Tenant-ID", Value: []byte(e.TenantID.String())},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("kafka publisher: %w", err)
+	}
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
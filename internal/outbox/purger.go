@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/repository"
+	"time"
+)
+
+const purgeInterval = time.Hour
+
+// Purger deletes old events from the outbox. It runs whether or not this
+// instance relays events, so the outbox stays bounded even when no relay
+// runs and events are only read by the /events stream.
+type Purger struct {
+	retention            time.Duration
+	unpublishedRetention time.Duration
+}
+
+func NewPurger(cfg config.OutboxConfig) *Purger {
+	return &Purger{retention: cfg.Retention, unpublishedRetention: cfg.UnpublishedRetention}
+}
+
+// Run purges once an hour until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	now := time.Now()
+	published, unpublished, err := repository.PurgeOutbox(ctx, now.Add(-p.retention), now.Add(-p.unpublishedRetention))
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to purge outbox", "error", err)
+		}
+		return
+	}
+	if published > 0 {
+		slog.Info("purged published outbox events", "count", published)
+	}
+	if unpublished > 0 {
+		slog.Warn("purged outbox events that were never published", "count", unpublished)
+	}
+}
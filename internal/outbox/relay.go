@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/metrics"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	"sync"
+	"time"
+)
+
+const (
+	claimBatch = 50
+	// publishTimeout bounds one Publish call. A batch is published
+	// concurrently, so the whole batch is done within publishTimeout and the
+	// lease margin covers saving the outcomes: an event is not picked up again
+	// while it is still being sent.
+	publishTimeout = 30 * time.Second
+	leaseMargin    = 30 * time.Second
+
+	backoffBase = time.Second
+	backoffMax  = 5 * time.Minute
+)
+
+// Relay moves saved events from the outbox to the publisher. An event is
+// retried until it is published, so a failing publisher holds back later
+// events of the same subscription but not of others.
+type Relay struct {
+	publisher Publisher
+	interval  time.Duration
+}
+
+func NewRelay(publisher Publisher, cfg config.OutboxConfig) *Relay {
+	return &Relay{publisher: publisher, interval: cfg.PollInterval}
+}
+
+// Run relays events until ctx is cancelled. The batch in progress is finished
+// before Run returns.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch suggests a backlog, so keep going without waiting.
+		if r.relay(ctx) == claimBatch && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publishes one claimed batch and returns its size. The batch holds at
+// most one event per subscription, so its events are published concurrently.
+func (r *Relay) relay(ctx context.Context) int {
+	events, err := repository.ClaimOutboxEvents(ctx, claimBatch, publishTimeout+leaseMargin)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to claim outbox events", "error", err)
+		}
+		return 0
+	}
+
+	// Publishing outlives ctx so that a shutdown does not leave a published
+	// event unrecorded, which would only cause a needless duplicate.
+	publishCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := range events {
+		wg.Add(1)
+		go func(e *model.OutboxEvent) {
+			defer wg.Done()
+			r.publish(publishCtx, e)
+		}(&events[i])
+	}
+	wg.Wait()
+
+	return len(events)
+}
+
+func (r *Relay) publish(ctx context.Context, e *model.OutboxEvent) {
+	log := slog.With("event_id", e.EventID, "type", e.EventType, "aggregate_id", e.AggregateID)
+
+	publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	err := r.publisher.Publish(publishCtx, ToEvent(*e))
+	cancel()
+
+	now := time.Now()
+	e.Attempts++
+	if err == nil {
+		e.PublishedAt = &now
+		e.LastError = ""
+		metrics.OutboxPublished.WithLabelValues("published").Inc()
+	} else {
+		e.NextAttemptAt = now.Add(backoff(e.Attempts))
+		e.LastError = err.Error()
+		metrics.OutboxPublished.WithLabelValues("failed").Inc()
+		log.Warn("failed to publish event, will retry", "attempts", e.Attempts, "next_attempt_at", e.NextAttemptAt, "error", err)
+	}
+
+	if err := repository.SaveOutboxAttempt(ctx, e); err != nil {
+		log.Error("failed to save outbox attempt", "error", err)
+	}
+}
+
+// ToEvent restores the published form of a stored event.
+func ToEvent(e model.OutboxEvent) event.Event {
+	return event.Event{
+		ID:          e.EventID,
+		Type:        e.EventType,
+		TenantID:    e.TenantID,
+		AggregateID: e.AggregateID,
+		OccurredAt:  e.CreatedAt.UTC(),
+		Data:        json.RawMessage(e.Payload),
+	}
+}
+
+func backoff(attempts int) time.Duration {
+	delay := backoffBase
+	for i := 1; i < attempts && delay < backoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, backoffMax)
+}
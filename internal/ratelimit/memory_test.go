@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+// step is one request: wait advances the clock before the key is taken.
+type step struct {
+	wait          time.Duration
+	key           string
+	wantAllowed   bool
+	wantRemaining int
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	// One token per second, up to three at once.
+	limit := Limit{Rate: 1, Burst: 3}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"burst is allowed at once", []step{
+			{0, "a", true, 2},
+			{0, "a", true, 1},
+			{0, "a", true, 0},
+			{0, "a", false, 0},
+		}},
+		{"tokens refill over time", []step{
+			{0, "a", true, 2},
+			{0, "a", true, 1},
+			{0, "a", true, 0},
+			{500 * time.Millisecond, "a", false, 0},
+			{500 * time.Millisecond, "a", true, 0},
+			{2 * time.Second, "a", true, 1},
+		}},
+		{"refill stops at burst", []step{
+			{0, "a", true, 2},
+			{time.Hour, "a", true, 2},
+			{0, "a", true, 1},
+		}},
+		{"keys have separate buckets", []step{
+			{0, "a", true, 2},
+			{0, "a", true, 1},
+			{0, "a", true, 0},
+			{0, "a", false, 0},
+			{0, "b", true, 2},
+			{0, "a", false, 0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestStore()
+			for i, s := range tt.steps {
+				clock.Advance(s.wait)
+				decision, err := store.Take(context.Background(), s.key, limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if decision.Allowed != s.wantAllowed || decision.Remaining != s.wantRemaining {
+					t.Fatalf("step %d (%s): allowed=%v remaining=%d, want allowed=%v remaining=%d",
+						i, s.key, decision.Allowed, decision.Remaining, s.wantAllowed, s.wantRemaining)
+				}
+				if decision.Limit != limit.Burst {
+					t.Errorf("step %d: limit = %d, want %d", i, decision.Limit, limit.Burst)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreTimings(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Rate: 0.5, Burst: 2}
+
+	tests := []struct {
+		wantAllowed    bool
+		wantRetryAfter time.Duration
+		wantReset      time.Duration
+	}{
+		{true, 0, 2 * time.Second},
+		{true, 0, 4 * time.Second},
+		{false, 2 * time.Second, 4 * time.Second},
+	}
+	for i, tt := range tests {
+		decision, err := store.Take(context.Background(), "a", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decision.Allowed != tt.wantAllowed || decision.RetryAfter != tt.wantRetryAfter || decision.Reset != tt.wantReset {
+			t.Errorf("take %d: allowed=%v retry_after=%v reset=%v, want %v %v %v",
+				i, decision.Allowed, decision.RetryAfter, decision.Reset, tt.wantAllowed, tt.wantRetryAfter, tt.wantReset)
+		}
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	// A taken token comes back after 100s.
+	limit := Limit{Rate: 0.01, Burst: 2}
+
+	tests := []struct {
+		name     string
+		wait     time.Duration
+		wantKept bool
+	}{
+		{"no sweep yet", 30 * time.Second, true},
+		{"refilling bucket survives a sweep", 70 * time.Second, true},
+		{"full bucket is dropped", 110 * time.Second, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestStore()
+			if _, err := store.Take(context.Background(), "idle", limit); err != nil {
+				t.Fatal(err)
+			}
+			clock.Advance(tt.wait)
+			if _, err := store.Take(context.Background(), "other", limit); err != nil {
+				t.Fatal(err)
+			}
+
+			store.mu.Lock()
+			_, kept := store.buckets["idle"]
+			store.mu.Unlock()
+			if kept != tt.wantKept {
+				t.Errorf("idle bucket kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"subscription-aggregator/internal/config"
+	"time"
+)
+
+// Limit describes a token bucket: it holds up to Burst tokens and refills at
+// Rate tokens per second. Every request takes one token.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func FromConfig(cfg config.RateLimit) Limit {
+	if cfg.Requests == 0 || cfg.Period == 0 {
+		return Limit{}
+	}
+	return Limit{Rate: float64(cfg.Requests) / cfg.Period.Seconds(), Burst: cfg.Burst}
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Window is the time an empty bucket needs to fill up completely.
+func (l Limit) Window() time.Duration {
+	return l.refill(float64(l.Burst))
+}
+
+// refill returns how long an empty bucket needs to gain n tokens.
+func (l Limit) refill(n float64) time.Duration {
+	return time.Duration(n / l.Rate * float64(time.Second))
+}
+
+// Decision is the outcome of taking a token. RetryAfter is set only when the
+// request is rejected; Reset is the time until the bucket is full again.
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+// Store keeps the buckets. The in-memory store suits a single instance;
+// deployments with several replicas need a shared implementation (e.g. Redis)
+// so that clients cannot multiply their limit by spreading requests.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+}
@@ -1,51 +1,78 @@
 package repository
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
-	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/logger"
+	"subscription-aggregator/internal/metrics"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
 )
 
 var DB *gorm.DB
 
-func InitAndMigrateDB() {
-	conStr := "host=" + os.Getenv("DB_HOST") +
-		" user=" + os.Getenv("DB_USER") +
-		" password=" + os.Getenv("DB_PASS") +
-		" dbname=" + os.Getenv("DB_NAME") +
-		" port=" + os.Getenv("DB_PORT") +
-		" sslmode=disable"
-
-	log.Println("starting database connection...")
+func InitDB(cfg config.DBConfig) {
+	slog.Info("starting database connection")
 
 	var err error
-	maxRetries := 10
-
-	for i := 1; i <= maxRetries; i++ {
-		log.Printf("attempting database connection (%d/%d)", i, maxRetries)
-		DB, err = gorm.Open(postgres.Open(conStr))
+	for i := 1; i <= cfg.ConnectRetries; i++ {
+		slog.Info("attempting database connection", "attempt", i, "max_retries", cfg.ConnectRetries)
+		DB, err = gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{Logger: logger.NewGormLogger()})
 		if err == nil {
-			log.Println("database connection established")
+			slog.Info("database connection established")
 			break
 		}
-		log.Printf("failed to connect to database: %v", err)
-		time.Sleep(2 * time.Second)
+		slog.Warn("failed to connect to database", "error", err)
+		time.Sleep(cfg.RetryInterval)
 	}
 
 	if DB == nil {
-		log.Fatal("unable to connect to database after retries")
+		slog.Error("unable to connect to database after retries")
+		os.Exit(1)
 	}
 
-	log.Println("starting auto migration...")
-
-	err = DB.AutoMigrate(&model.Subscription{})
+	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatalf("auto migration failed: %v", err)
+		slog.Error("failed to access database pool", "error", err)
+		os.Exit(1)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	// Pool and query metrics come from the metrics plugin, and query
+	// arguments are kept out of spans.
+	if err := DB.Use(otelgorm.NewPlugin(otelgorm.WithoutMetrics(), otelgorm.WithoutQueryVariables())); err != nil {
+		slog.Error("failed to register database tracing", "error", err)
+		os.Exit(1)
+	}
+	if err := DB.Use(TenantScope{}); err != nil {
+		slog.Error("failed to register tenant scoping", "error", err)
+		os.Exit(1)
 	}
+	if err := registerMetrics(); err != nil {
+		slog.Error("failed to register database metrics", "error", err)
+		os.Exit(1)
+	}
+}
 
-	log.Println("database migration completed")
+func operation(ctx context.Context, name string) *gorm.DB {
+	return DB.WithContext(ctx).Set(metrics.OperationKey, name)
+}
+
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	monthyear "subscription-aggregator/pkg/month-year"
+)
+
+type ServiceMonthStats struct {
+	Month       monthyear.MonthYear `json:"month"`
+	ServiceName string              `json:"service_name"`
+	Subscribers int                 `json:"subscribers"`
+	Revenue     int                 `json:"revenue"`
+}
+
+type ServicePriceStats struct {
+	ServiceName   string  `json:"service_name"`
+	Subscriptions int     `json:"subscriptions"`
+	AveragePrice  float64 `json:"average_price"`
+	MinPrice      int     `json:"min_price"`
+	MaxPrice      int     `json:"max_price"`
+}
+
+type MonthMovement struct {
+	Month            monthyear.MonthYear `json:"month"`
+	NewSubscriptions int                 `json:"new_subscriptions"`
+	Churned          int                 `json:"churned"`
+}
+
+func GetServiceMonthStats(ctx context.Context, from, to monthyear.MonthYear) ([]ServiceMonthStats, error) {
+	var stats []ServiceMonthStats
+	err := operation(ctx, "service_month_stats").Raw(`
+		SELECT m.month::date AS month,
+			LOWER(s.service_name) AS service_name,
+			COUNT(DISTINCT s.user_id) AS subscribers,
+			SUM(s.price) AS revenue
+		FROM generate_series(@from::date, @to::date, interval '1 month') AS m(month)
+		JOIN subscriptions s
+			ON s.tenant_id = @tenant_id
+			AND s.deleted_at IS NULL
+			AND s.start_date <= m.month
+			AND (s.end_date IS NULL OR s.end_date >= m.month)
+		GROUP BY m.month, LOWER(s.service_name)
+		ORDER BY m.month, service_name`,
+		map[string]interface{}{"from": from, "to": to, "tenant_id": TenantID(ctx)},
+	).Scan(&stats).Error
+
+	return stats, err
+}
+
+func GetServicePriceStats(ctx context.Context, from, to monthyear.MonthYear) ([]ServicePriceStats, error) {
+	var stats []ServicePriceStats
+	err := operation(ctx, "service_price_stats").Raw(`
+		SELECT LOWER(service_name) AS service_name,
+			COUNT(*) AS subscriptions,
+			AVG(price)::float8 AS average_price,
+			MIN(price) AS min_price,
+			MAX(price) AS max_price
+		FROM subscriptions
+		WHERE tenant_id = @tenant_id
+			AND deleted_at IS NULL
+			AND start_date <= @to::date
+			AND (end_date IS NULL OR end_date >= @from::date)
+		GROUP BY LOWER(service_name)
+		ORDER BY service_name`,
+		map[string]interface{}{"from": from, "to": to, "tenant_id": TenantID(ctx)},
+	).Scan(&stats).Error
+
+	return stats, err
+}
+
+func GetMonthMovements(ctx context.Context, from, to monthyear.MonthYear) ([]MonthMovement, error) {
+	var movements []MonthMovement
+	err := operation(ctx, "month_movements").Raw(`
+		SELECT m.month::date AS month,
+			COUNT(s.id) FILTER (WHERE s.start_date = m.month) AS new_subscriptions,
+			COUNT(s.id) FILTER (WHERE s.end_date = m.month) AS churned
+		FROM generate_series(@from::date, @to::date, interval '1 month') AS m(month)
+		LEFT JOIN subscriptions s
+			ON s.tenant_id = @tenant_id
+			AND s.deleted_at IS NULL
+			AND (s.start_date = m.month OR s.end_date = m.month)
+		GROUP BY m.month
+		ORDER BY m.month`,
+		map[string]interface{}{"from": from, "to": to, "tenant_id": TenantID(ctx)},
+	).Scan(&movements).Error
+
+	return movements, err
+}
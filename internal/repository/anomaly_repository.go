@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/google/uuid"
+)
+
+type PriceAnomaly struct {
+	Month       monthyear.MonthYear `json:"month"`
+	ID          uint                `json:"id"`
+	UserID      uuid.UUID           `json:"user_id"`
+	ServiceName string              `json:"service_name"`
+	Price       int                 `json:"price"`
+	StartDate   monthyear.MonthYear `json:"start_date"`
+	Median      float64             `json:"median"`
+	SampleSize  int                 `json:"sample_size"`
+	Ratio       *float64            `json:"ratio"`
+}
+
+// GetPriceAnomalies compares, for every month of the period, each active
+// subscription with the median price of all subscriptions of the same service
+// active that month. A subscription is reported once per month it stands out.
+func GetPriceAnomalies(ctx context.Context, from, to monthyear.MonthYear, factor float64, minSample int) ([]PriceAnomaly, error) {
+	var anomalies []PriceAnomaly
+	err := operation(ctx, "price_anomalies").Raw(`
+		WITH active AS (
+			SELECT m.month::date AS month, s.id, s.user_id, s.service_name, s.price, s.start_date,
+				LOWER(s.service_name) AS service
+			FROM generate_series(@from::date, @to::date, interval '1 month') AS m(month)
+			JOIN subscriptions s
+				ON s.tenant_id = @tenant_id
+				AND s.deleted_at IS NULL
+				AND s.start_date <= m.month
+				AND (s.end_date IS NULL OR s.end_date >= m.month)
+		), medians AS (
+			SELECT service, month,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY price) AS median,
+				COUNT(*) AS sample_size
+			FROM active
+			GROUP BY service, month
+			HAVING COUNT(*) >= @min_sample
+		)
+		SELECT s.month, s.id, s.user_id, s.service_name, s.price, s.start_date,
+			m.median, m.sample_size,
+			s.price / NULLIF(m.median, 0) AS ratio
+		FROM active s
+		JOIN medians m USING (service, month)
+		WHERE s.price > m.median * @factor OR s.price * @factor < m.median
+		ORDER BY s.month, ratio DESC NULLS FIRST, s.id`,
+		map[string]interface{}{"from": from, "to": to, "factor": factor, "min_sample": minSample, "tenant_id": TenantID(ctx)},
+	).Scan(&anomalies).Error
+
+	return anomalies, err
+}
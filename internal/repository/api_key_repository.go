@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lastUsedResolution limits last_used_at writes to one per key per interval.
+const lastUsedResolution = time.Minute
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrInactiveAPIKey = errors.New("api key is revoked or expired")
+)
+
+// ResolveAPIKey looks up an active key by its hash, records its use and
+// returns the principal it authenticates.
+func ResolveAPIKey(ctx context.Context, raw string) (auth.Principal, error) {
+	var key model.APIKey
+	err := operation(ctx, "api_key_lookup").Where("key_hash = ?", auth.HashAPIKey(raw)).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return auth.Principal{}, ErrInvalidAPIKey
+	}
+
+	err = operation(ctx, "api_key_touch").Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", key.ID, now.Add(-lastUsedResolution)).
+		UpdateColumn("last_used_at", now).
+		Error
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	principal := auth.Principal{
+		Admin:       key.Scopes.Has(auth.ScopeAdmin),
+		Method:      auth.MethodAPIKey,
+		KeyID:       key.ID,
+		Scopes:      key.Scopes,
+		TenantID:    key.TenantID,
+		TenantBound: true,
+	}
+	if key.UserID != nil && *key.UserID != uuid.Nil {
+		principal.UserID = *key.UserID
+	}
+	return principal, nil
+}
+
+// RotateAPIKey replaces the secret of an active key. Revoked and expired keys
+// fail with ErrInactiveAPIKey: rotation must not bring them back to life.
+func RotateAPIKey(ctx context.Context, id uint, keyHash, prefix string) error {
+	return operation(ctx, "api_key_rotate").Transaction(func(tx *gorm.DB) error {
+		var key model.APIKey
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&key, id).Error; err != nil {
+			return err
+		}
+		if !key.Active(time.Now()) {
+			return ErrInactiveAPIKey
+		}
+		return tx.Model(&key).Updates(map[string]interface{}{
+			"key_hash":     keyHash,
+			"prefix":       prefix,
+			"last_used_at": nil,
+		}).Error
+	})
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	monthyear "subscription-aggregator/pkg/month-year"
+)
+
+type CohortRetention struct {
+	Month        monthyear.MonthYear
+	OffsetMonths int
+	Size         int
+	Retained     int
+}
+
+func GetCohortRetention(
+	ctx context.Context,
+	from, to monthyear.MonthYear,
+	serviceName string,
+	offsets []int,
+) ([]CohortRetention, error) {
+	offsetList := make([]string, 0, len(offsets))
+	for _, offset := range offsets {
+		offsetList = append(offsetList, strconv.Itoa(offset))
+	}
+
+	serviceFilter := ""
+	if serviceName != "" {
+		serviceFilter = " AND LOWER(s.service_name) = LOWER(@service_name)"
+	}
+
+	var retention []CohortRetention
+	err := operation(ctx, "cohort_retention").Raw(`
+		SELECT s.start_date AS month,
+			o.offset_months,
+			COUNT(*) AS size,
+			COUNT(*) FILTER (
+				WHERE s.end_date IS NULL
+					OR s.end_date >= s.start_date + make_interval(months => o.offset_months)
+			) AS retained
+		FROM subscriptions s
+		CROSS JOIN unnest(ARRAY[`+strings.Join(offsetList, ",")+`]) AS o(offset_months)
+		WHERE s.tenant_id = @tenant_id
+			AND s.deleted_at IS NULL
+			AND s.start_date BETWEEN @from::date AND @to::date`+serviceFilter+`
+		GROUP BY s.start_date, o.offset_months
+		ORDER BY s.start_date, o.offset_months`,
+		map[string]interface{}{"from": from, "to": to, "service_name": serviceName, "tenant_id": TenantID(ctx)},
+	).Scan(&retention).Error
+
+	return retention, err
+}
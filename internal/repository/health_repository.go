@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"subscription-aggregator/internal/migrate"
+)
+
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func Migrator() (*migrate.Migrator, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB)
+}
+
+// CheckMigrations fails when the schema is dirty or behind the migrations
+// embedded in this binary.
+func CheckMigrations(ctx context.Context) error {
+	migrator, err := Migrator()
+	if err != nil {
+		return err
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if !status.Initialized {
+		return fmt.Errorf("schema is not initialized, %d migration(s) pending up to %d", len(status.Pending), status.Latest)
+	}
+	if status.Dirty {
+		return fmt.Errorf("schema version %d: %w", status.Current, migrate.ErrDirty)
+	}
+	if len(status.Pending) > 0 {
+		return fmt.Errorf("schema is at version %d, %d migration(s) pending up to %d", status.Current, len(status.Pending), status.Latest)
+	}
+	return nil
+}
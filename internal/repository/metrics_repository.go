@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"subscription-aggregator/internal/metrics"
+	"subscription-aggregator/internal/model"
+	monthyear "subscription-aggregator/pkg/month-year"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const metricsQueryTimeout = 5 * time.Second
+
+func registerMetrics() error {
+	if err := DB.Use(metrics.GormPlugin{}); err != nil {
+		return err
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "db_pool_max_open_connections",
+		Help: "Maximum number of open connections to the database.",
+	}, func() float64 { return float64(sqlDB.Stats().MaxOpenConnections) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "db_pool_open_connections",
+		Help: "Number of established connections, both in use and idle.",
+	}, func() float64 { return float64(sqlDB.Stats().OpenConnections) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "db_pool_in_use_connections",
+		Help: "Number of connections currently in use.",
+	}, func() float64 { return float64(sqlDB.Stats().InUse) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "db_pool_idle_connections",
+		Help: "Number of idle connections.",
+	}, func() float64 { return float64(sqlDB.Stats().Idle) })
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_pool_wait_count_total",
+		Help: "Total number of connections waited for.",
+	}, func() float64 { return float64(sqlDB.Stats().WaitCount) })
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "db_pool_wait_duration_seconds_total",
+		Help: "Total time blocked waiting for a new connection.",
+	}, func() float64 { return sqlDB.Stats().WaitDuration.Seconds() })
+
+	return nil
+}
+
+// RefreshMetrics recounts the subscription gauges every interval until ctx is
+// cancelled. The counts scan the whole table, so they are kept off the scrape
+// path.
+func RefreshMetrics(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := refreshSubscriptionMetrics(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("failed to refresh subscription metrics", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func refreshSubscriptionMetrics(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, metricsQueryTimeout)
+	defer cancel()
+
+	var active int64
+	month := monthyear.Current()
+	err := operation(ctx, "count_active_subscriptions").
+		Model(&model.Subscription{}).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", month, month).
+		Count(&active).
+		Error
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	err = operation(ctx, "count_subscriptions").
+		Model(&model.Subscription{}).
+		Count(&total).
+		Error
+	if err != nil {
+		return err
+	}
+
+	metrics.SubscriptionsActive.Set(float64(active))
+	metrics.SubscriptionsTotal.Set(float64(total))
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/metrics"
+	"subscription-aggregator/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const outboxBatch = 500
+
+// AddToOutbox records an event of eventType for every subscription. It must
+// be called with the transaction that saves the subscriptions, so that the
+// events exist exactly when the change is committed.
+func AddToOutbox(tx *gorm.DB, eventType string, subs ...model.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]model.OutboxEvent, 0, len(subs))
+	for _, sub := range subs {
+		e, err := event.New(eventType, sub.TenantID, sub)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, model.OutboxEvent{
+			TenantID:      e.TenantID,
+			EventID:       e.ID,
+			EventType:     e.Type,
+			AggregateID:   sub.ID,
+			Payload:       model.JSON(e.Data),
+			NextAttemptAt: now,
+		})
+	}
+
+	return tx.Set(metrics.OperationKey, "outbox_add").CreateInBatches(&rows, outboxBatch).Error
+}
+
+// ClaimOutboxEvents picks up to limit due events and hides them from other
+// relays for lease. Only the oldest unpublished event of each aggregate is
+// eligible, so events of one subscription are published in order even with
+// several relays running.
+func ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+	err := operation(ctx, "outbox_claim").Raw(`
+		UPDATE outbox_events
+		SET next_attempt_at = now() + make_interval(secs => @lease)
+		WHERE id IN (
+			SELECT o.id FROM outbox_events o
+			WHERE o.published_at IS NULL AND o.next_attempt_at <= now()
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox_events p
+					WHERE p.aggregate_id = o.aggregate_id AND p.published_at IS NULL AND p.id < o.id
+				)
+			ORDER BY o.id
+			LIMIT @limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		map[string]interface{}{"lease": lease.Seconds(), "limit": limit},
+	).Scan(&events).Error
+
+	slices.SortFunc(events, func(a, b model.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+	return events, err
+}
+
+// SaveOutboxAttempt stores the outcome of publishing an event.
+func SaveOutboxAttempt(ctx context.Context, e *model.OutboxEvent) error {
+	return operation(ctx, "outbox_attempt").
+		Model(e).
+		Select("attempts", "next_attempt_at", "last_error", "published_at").
+		Updates(e).
+		Error
+}
+
+// PurgeOutbox deletes events published before publishedBefore and events
+// that are still unpublished but were created before createdBefore.
+func PurgeOutbox(ctx context.Context, publishedBefore, createdBefore time.Time) (published, unpublished int64, err error) {
+	result := operation(ctx, "outbox_purge").
+		Where("published_at < ?", publishedBefore).
+		Delete(&model.OutboxEvent{})
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	published = result.RowsAffected
+
+	result = operation(ctx, "outbox_purge_unpublished").
+		Where("published_at IS NULL AND created_at < ?", createdBefore).
+		Delete(&model.OutboxEvent{})
+	return published, result.RowsAffected, result.Error
+}
+
+// LatestOutboxID returns the ID of the newest event in the outbox.
+func LatestOutboxID(ctx context.Context) (uint, error) {
+	var id uint
+	err := operation(ctx, "outbox_latest").
+		Model(&model.OutboxEvent{}).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&id).
+		Error
+	return id, err
+}
+
+// OutboxEventsByID returns the events with the given IDs that exist, in ID
+// order.
+func OutboxEventsByID(ctx context.Context, ids []uint) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+	err := operation(ctx, "outbox_by_id").
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&events).
+		Error
+	return events, err
+}
+
+// OutboxEventsAfter returns up to limit events with an ID greater than after,
+// published or not, in ID order.
+func OutboxEventsAfter(ctx context.Context, after uint, limit int) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+	err := operation(ctx, "outbox_tail").
+		Where("id > ?", after).
+		Order("id").
+		Limit(limit).
+		Find(&events).
+		Error
+	return events, err
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"subscription-aggregator/internal/auth"
+
+	"gorm.io/gorm"
+)
+
+// Owned restricts a single-table query to the rows of the authenticated user.
+// Admins and API keys not bound to a user are unrestricted; a context without
+// a principal matches nothing.
+func Owned(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		principal, ok := auth.FromContext(ctx)
+		switch {
+		case !ok:
+			return db.Where("1 = 0")
+		case principal.Unrestricted():
+			return db
+		default:
+			return db.Where("user_id = ?", principal.UserID)
+		}
+	}
+}
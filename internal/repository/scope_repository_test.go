@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/model"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type capturedStatement struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// useDryRunDB points DB at a Postgres dialector that never connects and
+// records the SQL of every statement instead of running it.
+func useDryRunDB(t *testing.T) *[]capturedStatement {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(TenantScope{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var captured []capturedStatement
+	capture := func(tx *gorm.DB) {
+		captured = append(captured, capturedStatement{SQL: tx.Statement.SQL.String(), Vars: tx.Statement.Vars})
+	}
+	for _, err := range []error{
+		db.Callback().Query().After("gorm:query").Register("test:capture", capture),
+		db.Callback().Row().After("gorm:row").Register("test:capture", capture),
+		db.Callback().Raw().After("gorm:raw").Register("test:capture", capture),
+		db.Callback().Create().After("gorm:create").Register("test:capture", capture),
+		db.Callback().Update().After("gorm:update").Register("test:capture", capture),
+		db.Callback().Delete().After("gorm:delete").Register("test:capture", capture),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() { DB = previous })
+	return &captured
+}
+
+func TestOwned(t *testing.T) {
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantWhere string
+		wantUser  bool
+	}{
+		{"no principal", context.Background(), "1 = 0", false},
+		{"user", auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID, Method: auth.MethodJWT}), "user_id = $", true},
+		{"user-bound API key", auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID, Method: auth.MethodAPIKey}), "user_id = $", true},
+		{"user-bound admin API key", auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID, Admin: true, Method: auth.MethodAPIKey, Scopes: []string{auth.ScopeAdmin}}), "user_id = $", true},
+		{"admin", auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID, Admin: true, Method: auth.MethodJWT}), "", false},
+		{"unbound API key", auth.WithPrincipal(context.Background(), auth.Principal{Method: auth.MethodAPIKey}), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captured := useDryRunDB(t)
+			var subs []model.Subscription
+			if err := DB.WithContext(tt.ctx).Scopes(Owned(tt.ctx)).Find(&subs).Error; err != nil {
+				t.Fatal(err)
+			}
+			stmt := (*captured)[0]
+
+			if tt.wantWhere != "" && !strings.Contains(stmt.SQL, tt.wantWhere) {
+				t.Fatalf("SQL %q does not contain %q", stmt.SQL, tt.wantWhere)
+			}
+			if tt.wantWhere == "" && strings.Contains(stmt.SQL, "user_id") {
+				t.Fatalf("SQL %q is restricted to a user", stmt.SQL)
+			}
+			hasUser := false
+			for _, v := range stmt.Vars {
+				if v == userID {
+					hasUser = true
+				}
+			}
+			if hasUser != tt.wantUser {
+				t.Fatalf("user_id bound = %v, want %v (vars %v)", hasUser, tt.wantUser, stmt.Vars)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"subscription-aggregator/internal/model"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/google/uuid"
+)
+
+type MonthlySpend struct {
+	Month monthyear.MonthYear `json:"month"`
+	Total int                 `json:"total"`
+}
+
+// SpendFilter narrows spend to one service or one category; empty fields
+// match everything.
+type SpendFilter struct {
+	ServiceName string
+	Category    string
+}
+
+func GetMonthlySpend(
+	ctx context.Context,
+	userID uuid.UUID,
+	filter SpendFilter,
+	from, to monthyear.MonthYear,
+) ([]MonthlySpend, error) {
+	extraFilter := ""
+	args := []interface{}{from, to, TenantID(ctx), userID}
+	if filter.ServiceName != "" {
+		extraFilter += " AND LOWER(s.service_name) = LOWER(?)"
+		args = append(args, filter.ServiceName)
+	}
+	if filter.Category != "" {
+		extraFilter += " AND LOWER(s.category) = LOWER(?)"
+		args = append(args, filter.Category)
+	}
+
+	var spend []MonthlySpend
+	err := operation(ctx, "monthly_spend").Raw(`
+		SELECT m.month::date AS month, COALESCE(SUM(s.price), 0) AS total
+		FROM generate_series(?::date, ?::date, interval '1 month') AS m(month)
+		LEFT JOIN subscriptions s
+			ON s.tenant_id = ?
+			AND s.user_id = ?
+			AND s.deleted_at IS NULL
+			AND s.start_date <= m.month
+			AND (s.end_date IS NULL OR s.end_date >= m.month)`+extraFilter+`
+		GROUP BY m.month
+		ORDER BY m.month`,
+		args...,
+	).Scan(&spend).Error
+
+	return spend, err
+}
+
+// GetNewSpendRate returns the average monthly price of subscriptions the user
+// started in the months from..to-1, i.e. how fast new recurring spend has been
+// added recently.
+func GetNewSpendRate(
+	ctx context.Context,
+	userID uuid.UUID,
+	filter SpendFilter,
+	from, to monthyear.MonthYear,
+) (float64, error) {
+	query := operation(ctx, "new_spend_rate").
+		Model(&model.Subscription{}).
+		Select("COALESCE(SUM(price), 0)").
+		Where("user_id = ? AND start_date >= ? AND start_date < ?", userID, from, to)
+	if filter.ServiceName != "" {
+		query = query.Where("LOWER(service_name) = LOWER(?)", filter.ServiceName)
+	}
+	if filter.Category != "" {
+		query = query.Where("LOWER(category) = LOWER(?)", filter.Category)
+	}
+
+	var total int64
+	if err := query.Scan(&total).Error; err != nil {
+		return 0, err
+	}
+
+	months := from.MonthsUntil(to)
+	if months <= 0 {
+		return 0, nil
+	}
+	return float64(total) / float64(months), nil
+}
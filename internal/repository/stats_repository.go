@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/google/uuid"
+)
+
+const userSubscriptionMonthsCTE = `
+	WITH bounded AS (
+		SELECT id, service_name, price, start_date,
+			LEAST(COALESCE(end_date, @month::date), @month::date) AS last_month
+		FROM subscriptions
+		WHERE tenant_id = @tenant_id AND user_id = @user_id AND deleted_at IS NULL AND start_date <= @month::date
+	), sub_months AS (
+		SELECT *,
+			(EXTRACT(YEAR FROM age(last_month, start_date)) * 12 +
+				EXTRACT(MONTH FROM age(last_month, start_date)) + 1)::int AS months
+		FROM bounded
+		WHERE last_month >= start_date
+	)`
+
+type UserStatsSummary struct {
+	ActiveCount   int                  `json:"active_count"`
+	LifetimeSpend int                  `json:"lifetime_spend"`
+	FirstMonth    *monthyear.MonthYear `json:"first_month"`
+}
+
+type ServicePrice struct {
+	ID          uint                `json:"id"`
+	ServiceName string              `json:"service_name"`
+	Price       int                 `json:"price"`
+	StartDate   monthyear.MonthYear `json:"start_date"`
+	Months      int                 `json:"months"`
+}
+
+func GetUserStatsSummary(ctx context.Context, userID uuid.UUID, month monthyear.MonthYear) (UserStatsSummary, error) {
+	var summary UserStatsSummary
+	err := operation(ctx, "user_stats_summary").Raw(userSubscriptionMonthsCTE+`
+		SELECT
+			COUNT(*) FILTER (WHERE last_month = @month::date) AS active_count,
+			COALESCE(SUM(price * months), 0) AS lifetime_spend,
+			MIN(start_date) AS first_month
+		FROM sub_months`,
+		map[string]interface{}{"tenant_id": TenantID(ctx), "user_id": userID, "month": month},
+	).Scan(&summary).Error
+
+	return summary, err
+}
+
+func GetMostExpensiveActiveSubscription(ctx context.Context, userID uuid.UUID, month monthyear.MonthYear) (*ServicePrice, error) {
+	return findUserSubscriptionMonths(ctx, userID, month, "WHERE last_month = @month::date ORDER BY price DESC, id")
+}
+
+func GetLongestRunningSubscription(ctx context.Context, userID uuid.UUID, month monthyear.MonthYear) (*ServicePrice, error) {
+	return findUserSubscriptionMonths(ctx, userID, month, "ORDER BY months DESC, id")
+}
+
+func findUserSubscriptionMonths(ctx context.Context, userID uuid.UUID, month monthyear.MonthYear, clause string) (*ServicePrice, error) {
+	var found []ServicePrice
+	err := operation(ctx, "user_subscription_months").Raw(userSubscriptionMonthsCTE+`
+		SELECT id, service_name, price, start_date, months
+		FROM sub_months `+clause+`
+		LIMIT 1`,
+		map[string]interface{}{"tenant_id": TenantID(ctx), "user_id": userID, "month": month},
+	).Scan(&found).Error
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+
+	return &found[0], nil
+}
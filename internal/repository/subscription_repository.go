@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"subscription-aggregator/internal/metrics"
+	"subscription-aggregator/internal/model"
+	monthyear "subscription-aggregator/pkg/month-year"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MonthlyTotal struct {
+	Month         monthyear.MonthYear `json:"month"`
+	Subscriptions int                 `json:"subscriptions"`
+	Total         int                 `json:"total"`
+}
+
+func FilterSubscriptions(ctx context.Context, userID, serviceName string) *gorm.DB {
+	query := DB.WithContext(ctx).Scopes(Owned(ctx))
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if serviceName != "" {
+		query = query.Where("service_name = ?", serviceName)
+	}
+	return query
+}
+
+func GetMonthlyTotals(ctx context.Context, userID, serviceName string, until monthyear.MonthYear) ([]MonthlyTotal, error) {
+	var totals []MonthlyTotal
+	err := FilterSubscriptions(ctx, userID, serviceName).
+		Set(metrics.OperationKey, "monthly_totals").
+		Model(&model.Subscription{}).
+		Select("m.month::date AS month, COUNT(*) AS subscriptions, SUM(price) AS total").
+		Joins(
+			"CROSS JOIN LATERAL generate_series(start_date, LEAST(COALESCE(end_date, ?::date), ?::date), interval '1 month') AS m(month)",
+			until,
+			until,
+		).
+		Group("m.month").
+		Order("m.month").
+		Scan(&totals).
+		Error
+
+	return totals, err
+}
+
+// LockUserSubscriptions takes a transaction-scoped advisory lock on the
+// subscriptions of one user, so that a check over them (e.g. for overlaps)
+// and the write that depends on it cannot interleave with another request
+// doing the same. The lock is released when tx commits or rolls back.
+func LockUserSubscriptions(tx *gorm.DB, userID uuid.UUID) error {
+	key := TenantID(tx.Statement.Context).String() + "/" + userID.String()
+	return tx.Set(metrics.OperationKey, "lock_user_subscriptions").
+		Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", key).
+		Error
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/model"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const tenantCacheTTL = 30 * time.Second
+
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// TenantID returns the tenant of the authenticated caller. Without a
+// principal it is uuid.Nil, which matches no rows in tenant filters.
+func TenantID(ctx context.Context) uuid.UUID {
+	principal, _ := auth.FromContext(ctx)
+	return principal.TenantID
+}
+
+// TenantScope is a GORM plugin that confines every model-based statement on a
+// table with a tenant_id column to the caller's tenant: queries, updates and
+// deletes get a tenant_id condition and inserts get tenant_id assigned. Raw
+// SQL is not touched and must filter by TenantID(ctx) itself. Statements whose
+// context carries no principal (startup, metrics, background jobs) are left
+// unscoped.
+type TenantScope struct{}
+
+func (TenantScope) Name() string {
+	return "tenant_scope"
+}
+
+func (TenantScope) Initialize(db *gorm.DB) error {
+	return errors.Join(
+		db.Callback().Create().Before("gorm:create").Register("tenant:assign", assignTenant),
+		db.Callback().Query().Before("gorm:query").Register("tenant:scope_query", scopeTenant),
+		db.Callback().Row().Before("gorm:row").Register("tenant:scope_row", scopeTenant),
+		db.Callback().Update().Before("gorm:update").Register("tenant:scope_update", scopeTenantUpdate),
+		db.Callback().Delete().Before("gorm:delete").Register("tenant:scope_delete", scopeTenant),
+	)
+}
+
+func tenantField(db *gorm.DB) (*schema.Field, auth.Principal, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, auth.Principal{}, false
+	}
+	field := db.Statement.Schema.LookUpField("tenant_id")
+	if field == nil {
+		return nil, auth.Principal{}, false
+	}
+	principal, ok := auth.FromContext(db.Statement.Context)
+	return field, principal, ok
+}
+
+func scopeTenant(db *gorm.DB) {
+	field, principal, ok := tenantField(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: principal.TenantID},
+	}})
+}
+
+func scopeTenantUpdate(db *gorm.DB) {
+	// Rows never move between tenants, whatever the update map says.
+	if changes, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		delete(changes, "tenant_id")
+		delete(changes, "TenantID")
+	}
+	scopeTenant(db)
+}
+
+func assignTenant(db *gorm.DB) {
+	field, principal, ok := tenantField(db)
+	if !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := field.Set(ctx, reflect.Indirect(rv.Index(i)), principal.TenantID); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(ctx, rv, principal.TenantID); err != nil {
+			db.AddError(err)
+		}
+	}
+}
+
+type cachedTenant struct {
+	tenant  model.Tenant
+	expires time.Time
+}
+
+var tenantCache sync.Map
+
+// GetTenant returns a tenant by ID, served from a short-lived cache because
+// it is consulted on every authenticated request.
+func GetTenant(ctx context.Context, id uuid.UUID) (model.Tenant, error) {
+	if cached, ok := tenantCache.Load(id); ok && time.Now().Before(cached.(cachedTenant).expires) {
+		return cached.(cachedTenant).tenant, nil
+	}
+
+	var tenant model.Tenant
+	err := operation(ctx, "tenant_lookup").First(&tenant, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Tenant{}, ErrTenantNotFound
+	}
+	if err != nil {
+		return model.Tenant{}, err
+	}
+
+	tenantCache.Store(id, cachedTenant{tenant: tenant, expires: time.Now().Add(tenantCacheTTL)})
+	return tenant, nil
+}
+
+func TenantExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	_, err := GetTenant(ctx, id)
+	if errors.Is(err, ErrTenantNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CurrentTenant returns the tenant of the authenticated caller.
+func CurrentTenant(ctx context.Context) (model.Tenant, error) {
+	return GetTenant(ctx, TenantID(ctx))
+}
+
+func ForgetTenant(id uuid.UUID) {
+	tenantCache.Delete(id)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"subscription-aggregator/internal/auth"
+	"subscription-aggregator/internal/model"
+	monthyear "subscription-aggregator/pkg/month-year"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	tenantA = uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	tenantB = uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
+)
+
+// tenantContext is a tenant B caller; tenant A's rows must stay invisible.
+func tenantContext(admin bool) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{
+		UserID:      uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		Admin:       admin,
+		Method:      auth.MethodJWT,
+		TenantID:    tenantB,
+		TenantBound: true,
+	})
+}
+
+// assertTenantFiltered checks that every captured statement compares a
+// tenant_id column with a placeholder bound to tenant B and never binds
+// tenant A.
+func assertTenantFiltered(t *testing.T, captured []capturedStatement) {
+	t.Helper()
+	if len(captured) == 0 {
+		t.Fatal("no statement was built")
+	}
+	for _, stmt := range captured {
+		filtered := false
+		for i, v := range stmt.Vars {
+			if v == tenantA {
+				t.Fatalf("statement binds tenant A: %s", stmt.SQL)
+			}
+			placeholder := regexp.MustCompile(fmt.Sprintf(`tenant_id"? = \$%d\b`, i+1))
+			if v == tenantB && placeholder.MatchString(stmt.SQL) {
+				filtered = true
+			}
+		}
+		if !filtered {
+			t.Fatalf("statement is not filtered by tenant B: %s %v", stmt.SQL, stmt.Vars)
+		}
+	}
+}
+
+// ignoreDryRun drops the error dry-run mode returns for statements that scan
+// rows, since only the built SQL matters here.
+func ignoreDryRun(err error) error {
+	if errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		return nil
+	}
+	return err
+}
+
+func TestTenantScopeModelStatements(t *testing.T) {
+	ctx := tenantContext(true)
+
+	tests := []struct {
+		name string
+		run  func(db *gorm.DB) error
+	}{
+		{"find", func(db *gorm.DB) error {
+			var subs []model.Subscription
+			return db.Find(&subs).Error
+		}},
+		{"first by id", func(db *gorm.DB) error {
+			var sub model.Subscription
+			return db.First(&sub, 1).Error
+		}},
+		{"count", func(db *gorm.DB) error {
+			var count int64
+			return db.Model(&model.Subscription{}).Count(&count).Error
+		}},
+		{"pluck", func(db *gorm.DB) error {
+			var names []string
+			return db.Model(&model.Subscription{}).Pluck("service_name", &names).Error
+		}},
+		{"update", func(db *gorm.DB) error {
+			return db.Model(&model.Subscription{}).Where("id = ?", 1).Updates(map[string]interface{}{"price": 100, "tenant_id": tenantA}).Error
+		}},
+		{"delete", func(db *gorm.DB) error {
+			return db.Where("id = ?", 1).Delete(&model.Subscription{}).Error
+		}},
+		{"budgets", func(db *gorm.DB) error {
+			var budgets []model.Budget
+			return db.Find(&budgets).Error
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captured := useDryRunDB(t)
+			if err := ignoreDryRun(tt.run(DB.WithContext(ctx))); err != nil {
+				t.Fatal(err)
+			}
+			assertTenantFiltered(t, *captured)
+		})
+	}
+}
+
+func TestTenantScopeAssignsTenantOnCreate(t *testing.T) {
+	useDryRunDB(t)
+	ctx := tenantContext(true)
+
+	single := model.Subscription{TenantID: tenantA, ServiceName: "Netflix", Price: 100}
+	if err := DB.WithContext(ctx).Create(&single).Error; err != nil {
+		t.Fatal(err)
+	}
+	if single.TenantID != tenantB {
+		t.Fatalf("created row tenant = %s, want %s", single.TenantID, tenantB)
+	}
+
+	batch := []model.Subscription{{TenantID: tenantA}, {}}
+	if err := DB.WithContext(ctx).Create(&batch).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i, sub := range batch {
+		if sub.TenantID != tenantB {
+			t.Fatalf("batch row %d tenant = %s, want %s", i, sub.TenantID, tenantB)
+		}
+	}
+}
+
+func TestRawQueriesFilterByTenant(t *testing.T) {
+	ctx := tenantContext(true)
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	from := monthyear.MonthYear{Time: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	to := monthyear.MonthYear{Time: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"service month stats", func() error { _, err := GetServiceMonthStats(ctx, from, to); return err }},
+		{"service price stats", func() error { _, err := GetServicePriceStats(ctx, from, to); return err }},
+		{"month movements", func() error { _, err := GetMonthMovements(ctx, from, to); return err }},
+		{"price anomalies", func() error { _, err := GetPriceAnomalies(ctx, from, to, 3, 5); return err }},
+		{"cohort retention", func() error { _, err := GetCohortRetention(ctx, from, to, "netflix", []int{1, 3}); return err }},
+		{"user stats summary", func() error { _, err := GetUserStatsSummary(ctx, userID, to); return err }},
+		{"most expensive subscription", func() error { _, err := GetMostExpensiveActiveSubscription(ctx, userID, to); return err }},
+		{"longest running subscription", func() error { _, err := GetLongestRunningSubscription(ctx, userID, to); return err }},
+		{"monthly spend", func() error {
+			_, err := GetMonthlySpend(ctx, userID, SpendFilter{ServiceName: "netflix"}, from, to)
+			return err
+		}},
+		{"new spend rate", func() error {
+			_, err := GetNewSpendRate(ctx, userID, SpendFilter{Category: "video"}, from, to)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captured := useDryRunDB(t)
+			if err := ignoreDryRun(tt.run()); err != nil {
+				t.Fatal(err)
+			}
+			assertTenantFiltered(t, *captured)
+		})
+	}
+}
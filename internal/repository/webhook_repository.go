@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/model"
+	monthyear "subscription-aggregator/pkg/month-year"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const webhookEnqueueBatch = 500
+
+// EnqueueWebhookEvents queues a delivery of every event to each active
+// endpoint of the event's tenant that subscribed to its type. An event already
+// queued for an endpoint is skipped, so producers may safely repeat events.
+func EnqueueWebhookEvents(ctx context.Context, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tenantIDs := map[uuid.UUID]bool{}
+	for _, e := range events {
+		tenantIDs[e.TenantID] = true
+	}
+	ids := make([]uuid.UUID, 0, len(tenantIDs))
+	for id := range tenantIDs {
+		ids = append(ids, id)
+	}
+
+	var endpoints []model.WebhookEndpoint
+	err := operation(ctx, "webhook_endpoints").
+		Where("tenant_id IN ? AND disabled_at IS NULL", ids).
+		Find(&endpoints).
+		Error
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []model.WebhookDelivery
+	for _, e := range events {
+		var payload []byte
+		for _, endpoint := range endpoints {
+			if endpoint.TenantID != e.TenantID || !endpoint.Events.Has(e.Type) {
+				continue
+			}
+			if payload == nil {
+				if payload, err = json.Marshal(e); err != nil {
+					return err
+				}
+			}
+			deliveries = append(deliveries, model.WebhookDelivery{
+				TenantID:      e.TenantID,
+				WebhookID:     endpoint.ID,
+				EventID:       e.ID,
+				EventType:     e.Type,
+				Payload:       payload,
+				Status:        model.DeliveryPending,
+				NextAttemptAt: &now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return operation(ctx, "webhook_enqueue").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&deliveries, webhookEnqueueBatch).
+		Error
+}
+
+// ClaimWebhookDeliveries picks up to limit pending deliveries that are due
+// and hides them from other dispatchers for lease. A delivery whose
+// dispatcher dies mid-attempt becomes due again once the lease runs out.
+func ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := operation(ctx, "webhook_claim").Raw(`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = now() + make_interval(secs => @lease), updated_at = now()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id
+			LIMIT @limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		map[string]interface{}{"lease": lease.Seconds(), "limit": limit},
+	).Scan(&deliveries).Error
+
+	return deliveries, err
+}
+
+func GetWebhookEndpoints(ctx context.Context, ids []uint) (map[uint]model.WebhookEndpoint, error) {
+	var endpoints []model.WebhookEndpoint
+	if err := operation(ctx, "webhook_endpoints").Where("id IN ?", ids).Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]model.WebhookEndpoint, len(endpoints))
+	for _, endpoint := range endpoints {
+		byID[endpoint.ID] = endpoint
+	}
+	return byID, nil
+}
+
+// SaveWebhookAttempt stores the outcome of a delivery attempt.
+func SaveWebhookAttempt(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return operation(ctx, "webhook_attempt").
+		Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "last_error", "delivered_at").
+		Updates(delivery).
+		Error
+}
+
+// RedeliverWebhook queues a copy of a delivery that is retried from scratch;
+// the original row keeps its attempt history.
+func RedeliverWebhook(ctx context.Context, id uint) (model.WebhookDelivery, error) {
+	var original model.WebhookDelivery
+	if err := operation(ctx, "webhook_redeliver").First(&original, id).Error; err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	now := time.Now()
+	copied := model.WebhookDelivery{
+		TenantID:      original.TenantID,
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        model.DeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}
+	if original.RedeliveryOf != nil {
+		copied.RedeliveryOf = original.RedeliveryOf
+	}
+	err := operation(ctx, "webhook_redeliver").Create(&copied).Error
+	return copied, err
+}
+
+// EachUpcomingRenewal calls fn with batches of subscriptions that are charged
+// again at the start of month, for tenants with an endpoint listening to
+// renewal events. A subscription is charged every billing cycle counted from
+// its start date. It runs across all tenants.
+func EachUpcomingRenewal(ctx context.Context, month monthyear.MonthYear, fn func([]model.Subscription) error) error {
+	var batch []model.Subscription
+	return operation(ctx, "upcoming_renewals").
+		Where("start_date < ? AND (end_date IS NULL OR end_date >= ?)", month, month).
+		Where(`mod(((EXTRACT(YEAR FROM ?::date) - EXTRACT(YEAR FROM start_date)) * 12
+			+ EXTRACT(MONTH FROM ?::date) - EXTRACT(MONTH FROM start_date))::int, ?) = 0`,
+			month, month, billingCycleMonths()).
+		Where(`EXISTS (
+			SELECT 1 FROM webhook_endpoints w
+			WHERE w.tenant_id = subscriptions.tenant_id
+				AND w.disabled_at IS NULL
+				AND ? = ANY(w.events))`, event.SubscriptionRenewalUpcoming).
+		FindInBatches(&batch, webhookEnqueueBatch, func(*gorm.DB, int) error {
+			return fn(batch)
+		}).
+		Error
+}
+
+// billingCycleMonths renders model.BillingCycleMonths as a CASE over
+// billing_cycle. Unknown cycles count as monthly, as in the calendar feed.
+func billingCycleMonths() clause.Expr {
+	cycles := make([]string, 0, len(model.BillingCycleMonths))
+	for cycle := range model.BillingCycleMonths {
+		cycles = append(cycles, cycle)
+	}
+	sort.Strings(cycles)
+
+	sql := "CASE billing_cycle"
+	vars := make([]interface{}, 0, 2*len(cycles))
+	for _, cycle := range cycles {
+		sql += " WHEN ? THEN ?"
+		vars = append(vars, cycle, model.BillingCycleMonths[cycle])
+	}
+	return gorm.Expr(sql+" ELSE 1 END", vars...)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"subscription-aggregator/internal/model"
+	monthyear "subscription-aggregator/pkg/month-year"
+	"testing"
+	"time"
+)
+
+func TestEachUpcomingRenewalFiltersByBillingCycle(t *testing.T) {
+	captured := useDryRunDB(t)
+	month := monthyear.MonthYear{Time: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)}
+	err := EachUpcomingRenewal(context.Background(), month, func([]model.Subscription) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*captured) != 1 {
+		t.Fatalf("got %d statements, want 1", len(*captured))
+	}
+
+	statement := (*captured)[0]
+	if !strings.Contains(statement.SQL, "CASE billing_cycle") || !strings.Contains(statement.SQL, "mod(") {
+		t.Fatalf("renewals not filtered by billing cycle: %s", statement.SQL)
+	}
+	for cycle, months := range model.BillingCycleMonths {
+		if !containsPair(statement.Vars, cycle, months) {
+			t.Errorf("cycle %s (%d months) not bound: %v", cycle, months, statement.Vars)
+		}
+	}
+}
+
+func containsPair(vars []interface{}, cycle string, months int) bool {
+	for i := 0; i+1 < len(vars); i++ {
+		if vars[i] == cycle && vars[i+1] == months {
+			return true
+		}
+	}
+	return false
+}
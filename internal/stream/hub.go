@@ -0,0 +1,342 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/outbox"
+	"subscription-aggregator/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	pollBatch = 500
+	// subscriberBuffer is how many events a slow client may fall behind
+	// before it is disconnected; it resumes with Last-Event-ID.
+	subscriberBuffer = 256
+	// gapTimeout is how long a missing outbox ID holds back later events.
+	// IDs are taken at insert but become visible at commit, so a gap is
+	// usually a transaction still in flight; one that stays open longer is
+	// skipped, and its IDs are looked up again for gapRecheck in case the
+	// transaction was only slow. Events found then are streamed late.
+	gapTimeout = 5 * time.Second
+	gapRecheck = 5 * time.Minute
+	// maxSkipped caps the skipped IDs being rechecked; a rolled back bulk
+	// insert can leave thousands of them.
+	maxSkipped = 10000
+)
+
+var ErrUnavailable = errors.New("event stream unavailable")
+
+// Entry is one event as sent to stream clients. Data is the JSON of the
+// event; the tenant and owner are kept for filtering.
+type Entry struct {
+	ID       uint
+	Type     string
+	TenantID uuid.UUID
+	UserID   uuid.UUID
+	Data     []byte
+}
+
+func NewEntry(e model.OutboxEvent) (Entry, error) {
+	data, err := json.Marshal(outbox.ToEvent(e))
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var owner struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := json.Unmarshal(e.Payload, &owner); err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{ID: e.ID, Type: e.EventType, TenantID: e.TenantID, UserID: owner.UserID, Data: data}, nil
+}
+
+// Filter selects the events of one tenant and, unless UserID is nil, of one
+// user.
+type Filter struct {
+	TenantID uuid.UUID
+	UserID   uuid.UUID
+}
+
+func (f Filter) Match(e Entry) bool {
+	return e.TenantID == f.TenantID && (f.UserID == uuid.Nil || e.UserID == f.UserID)
+}
+
+type Subscription struct {
+	events chan Entry
+	filter Filter
+}
+
+// Events delivers live events. It is closed when the hub shuts down or the
+// subscriber falls too far behind.
+func (s *Subscription) Events() <-chan Entry {
+	return s.events
+}
+
+// Hub tails the outbox and fans new events out to the connected clients. The
+// outbox is shared by all replicas, so every instance streams every change.
+type Hub struct {
+	interval  time.Duration
+	heartbeat time.Duration
+	size      int
+
+	mu     sync.Mutex
+	ready  bool
+	closed bool
+	cursor uint // ID of the newest streamed event
+	since  uint // buffer holds every streamed event with a greater ID
+	buffer []Entry
+	subs   map[*Subscription]struct{}
+
+	// Owned by the polling goroutine.
+	gapSince time.Time
+	skipped  map[uint]time.Time // IDs passed over at a gap and when
+}
+
+func NewHub(cfg config.EventsConfig) *Hub {
+	return &Hub{
+		interval:  cfg.PollInterval,
+		heartbeat: cfg.Heartbeat,
+		size:      cfg.BufferSize,
+		subs:      map[*Subscription]struct{}{},
+		skipped:   map[uint]time.Time{},
+	}
+}
+
+func (h *Hub) Heartbeat() time.Duration {
+	return h.heartbeat
+}
+
+// Subscribe registers a client. With resume set, the events after lastID
+// are returned too: from the buffer when it reaches back far enough, or else
+// replayTo is set and the caller reads the events up to it from the outbox.
+func (h *Hub) Subscribe(filter Filter, lastID uint, resume bool) (sub *Subscription, backlog []Entry, replayTo uint, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.ready || h.closed {
+		return nil, nil, 0, ErrUnavailable
+	}
+
+	sub = &Subscription{events: make(chan Entry, subscriberBuffer), filter: filter}
+	h.subs[sub] = struct{}{}
+
+	if !resume || lastID >= h.cursor {
+		return sub, nil, 0, nil
+	}
+	if lastID < h.since {
+		return sub, nil, h.cursor, nil
+	}
+	for _, e := range h.buffer {
+		if e.ID > lastID && filter.Match(e) {
+			backlog = append(backlog, e)
+		}
+	}
+	return sub, backlog, 0, nil
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Close disconnects all clients; the server calls it on shutdown so that
+// open streams do not hold it up.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Run polls the outbox until ctx is cancelled and then closes the hub.
+func (h *Hub) Run(ctx context.Context) {
+	defer h.Close()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch suggests a backlog, so keep going without waiting.
+		if h.poll(ctx) == pollBatch && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll streams the events committed since the last poll and returns how many
+// it consumed; it stops early at a gap that may still be filled.
+func (h *Hub) poll(ctx context.Context) int {
+	h.mu.Lock()
+	ready, cursor := h.ready, h.cursor
+	h.mu.Unlock()
+
+	// Clients see only changes made after the hub started.
+	if !ready {
+		latest, err := repository.LatestOutboxID(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("failed to start event stream", "error", err)
+			}
+			return 0
+		}
+		h.mu.Lock()
+		h.ready, h.cursor, h.since = true, latest, latest
+		h.mu.Unlock()
+		return 0
+	}
+
+	rows, err := repository.OutboxEventsAfter(ctx, cursor, pollBatch)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to read outbox for event stream", "error", err)
+		}
+		return 0
+	}
+
+	entries := h.recheckSkipped(ctx)
+	consumed := 0
+	for _, row := range rows {
+		if row.ID != cursor+1 {
+			if h.gapSince.IsZero() {
+				h.gapSince = time.Now()
+			}
+			if time.Since(h.gapSince) < gapTimeout {
+				break
+			}
+			h.skip(cursor+1, row.ID)
+		}
+		h.gapSince = time.Time{}
+		cursor = row.ID
+		consumed++
+
+		if entry, ok := decode(row); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	h.mu.Lock()
+	h.cursor = cursor
+	h.broadcast(entries)
+	h.mu.Unlock()
+
+	return consumed
+}
+
+// skip records the IDs in [from, to) as passed over.
+func (h *Hub) skip(from, to uint) {
+	now := time.Now()
+	for id := from; id < to; id++ {
+		if len(h.skipped) >= maxSkipped {
+			slog.Warn("too many outbox gaps, events committed late may not be streamed", "from", id, "to", to)
+			return
+		}
+		h.skipped[id] = now
+	}
+}
+
+// recheckSkipped returns the skipped events that have been committed since,
+// and forgets IDs skipped more than gapRecheck ago.
+func (h *Hub) recheckSkipped(ctx context.Context) []Entry {
+	if len(h.skipped) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(h.skipped))
+	for id, at := range h.skipped {
+		if time.Since(at) > gapRecheck {
+			delete(h.skipped, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := repository.OutboxEventsByID(ctx, ids)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to recheck outbox gaps for event stream", "error", err)
+		}
+		return nil
+	}
+
+	var entries []Entry
+	for _, row := range rows {
+		delete(h.skipped, row.ID)
+		slog.Warn("streaming outbox event committed after its gap was skipped", "id", row.ID)
+		if entry, ok := decode(row); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func decode(row model.OutboxEvent) (Entry, bool) {
+	entry, err := NewEntry(row)
+	if err != nil {
+		slog.Error("failed to decode outbox event", "id", row.ID, "error", err)
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// broadcast must be called with mu held.
+func (h *Hub) broadcast(entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	// Late events make IDs in the buffer non-monotonic, so since is the
+	// highest ID dropped rather than the last one.
+	h.buffer = append(h.buffer, entries...)
+	if over := len(h.buffer) - h.size; over > 0 {
+		for _, e := range h.buffer[:over] {
+			h.since = max(h.since, e.ID)
+		}
+		h.buffer = append([]Entry(nil), h.buffer[over:]...)
+	}
+
+	for sub := range h.subs {
+		for _, e := range entries {
+			if !sub.filter.Match(e) {
+				continue
+			}
+			select {
+			case sub.events <- e:
+			default:
+				slog.Warn("event stream client too slow, disconnecting")
+				delete(h.subs, sub)
+				close(sub.events)
+			}
+			if _, ok := h.subs[sub]; !ok {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// blockedPrefixes are ranges that are not covered by the netip predicates but
+// still reach infrastructure rather than the public internet.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// PublicAddress reports whether a webhook may be sent to addr. Loopback,
+// private, link-local (including the 169.254.169.254 metadata service),
+// multicast and reserved addresses are refused.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL resolves the host of a webhook URL and fails with
+// ErrForbiddenAddress if any of its addresses is not public, unless
+// allowPrivate is set. The dispatcher checks again when connecting, since DNS
+// answers may change afterwards.
+func CheckURL(ctx context.Context, rawURL string, allowPrivate bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", u.Hostname(), err)
+	}
+	if allowPrivate {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !PublicAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, u.Hostname(), addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// newDialer refuses connections to non-public addresses unless allowPrivate
+// is set. The check runs on the address actually dialled, after DNS
+// resolution, so a host that resolves to a public address at registration and
+// to an internal one later is still stopped.
+func newDialer(timeout time.Duration, allowPrivate bool) *net.Dialer {
+	if allowPrivate {
+		return &net.Dialer{Timeout: timeout}
+	}
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !PublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"1.1.1.1", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"100.64.0.1", false},
+		{"192.0.0.8", false},
+		{"198.18.0.1", false},
+		{"240.0.0.1", false},
+		{"255.255.255.255", false},
+		{"224.0.0.1", false},
+		{"ff02::1", false},
+		{"64:ff9b::a9fe:a9fe", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:8.8.8.8", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := PublicAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("PublicAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		allowPrivate bool
+		wantErr      error
+	}{
+		{"public", "https://8.8.8.8/hook", false, nil},
+		{"loopback", "http://127.0.0.1:8080/hook", false, ErrForbiddenAddress},
+		{"metadata service", "http://169.254.169.254/latest/meta-data", false, ErrForbiddenAddress},
+		{"IPv6 loopback", "http://[::1]/hook", false, ErrForbiddenAddress},
+		{"loopback allowed", "http://127.0.0.1:8080/hook", true, nil},
+		{"private allowed", "http://10.0.0.5/hook", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckURL(context.Background(), tt.url, tt.allowPrivate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckURL(%s) = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/metrics"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	"sync"
+	"time"
+)
+
+const (
+	claimBatch = 20
+	// leaseMargin is added to the request timeout when claiming deliveries so
+	// that a slow attempt is saved before anyone else may pick the row up.
+	leaseMargin = 30 * time.Second
+	// drainLimit caps how much of a response is read so that the connection
+	// can be reused. Bodies are never stored: last_error is shown to tenant
+	// admins and must not echo what an internal service answered.
+	drainLimit = 4 << 10
+)
+
+var errDisabled = errors.New("webhook disabled")
+
+// Dispatcher sends queued deliveries and reschedules failed ones with
+// exponential backoff. Several instances may run against the same database.
+type Dispatcher struct {
+	cfg    config.WebhooksConfig
+	client *http.Client
+}
+
+// NewDispatcher builds a client that only connects to public addresses (unless
+// cfg.AllowPrivateTargets is set), does not follow redirects (a 3xx is a
+// failed attempt) and ignores proxy settings, so tenant-supplied URLs cannot
+// reach internal services.
+func NewDispatcher(cfg config.WebhooksConfig) *Dispatcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = newDialer(cfg.Timeout, cfg.AllowPrivateTargets).DialContext
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &Dispatcher{cfg: cfg, client: client}
+}
+
+// Run polls for due deliveries until ctx is cancelled. Attempts that are
+// already in flight are finished before Run returns.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch suggests a backlog, so keep going without waiting.
+		if d.dispatch(ctx) == claimBatch && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch sends one claimed batch and returns its size.
+func (d *Dispatcher) dispatch(ctx context.Context) int {
+	deliveries, err := repository.ClaimWebhookDeliveries(ctx, claimBatch, d.cfg.Timeout+leaseMargin)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to claim webhook deliveries", "error", err)
+		}
+		return 0
+	}
+	if len(deliveries) == 0 {
+		return 0
+	}
+
+	ids := make([]uint, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.WebhookID)
+	}
+	endpoints, err := repository.GetWebhookEndpoints(ctx, ids)
+	if err != nil {
+		slog.Error("failed to load webhook endpoints", "error", err)
+		return 0
+	}
+
+	// Attempts outlive ctx so that a shutdown does not cut requests short
+	// and leave their outcome unsaved.
+	attemptCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *model.WebhookDelivery) {
+			defer wg.Done()
+			d.attempt(attemptCtx, delivery, endpoints[delivery.WebhookID])
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries)
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *model.WebhookDelivery, endpoint model.WebhookEndpoint) {
+	log := slog.With("delivery_id", delivery.ID, "webhook_id", delivery.WebhookID, "event_type", delivery.EventType)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+
+	var err error
+	if endpoint.ID == 0 || endpoint.DisabledAt != nil {
+		err = errDisabled
+	} else {
+		err = d.send(ctx, delivery, endpoint)
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		log.Info("webhook delivered", "attempts", delivery.Attempts)
+	case errors.Is(err, errDisabled) || errors.Is(err, ErrForbiddenAddress) || delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = model.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = err.Error()
+		log.Warn("webhook delivery failed permanently", "attempts", delivery.Attempts, "error", err)
+	default:
+		next := now.Add(d.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = err.Error()
+		log.Warn("webhook delivery failed, will retry", "attempts", delivery.Attempts, "next_attempt_at", next, "error", err)
+	}
+	metrics.WebhookDeliveries.WithLabelValues(delivery.Status).Inc()
+
+	if err := repository.SaveWebhookAttempt(ctx, delivery); err != nil {
+		log.Error("failed to save webhook attempt", "error", err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *model.WebhookDelivery, endpoint model.WebhookEndpoint) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "subscription-aggregator-webhooks")
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, time.Now(), delivery.Payload))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(EventIDHeader, delivery.EventID.String())
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit))
+
+	status := resp.StatusCode
+	delivery.ResponseStatus = &status
+	if status >= 200 && status < 300 {
+		return nil
+	}
+	return fmt.Errorf("unexpected status %d", status)
+}
+
+// backoff doubles the delay after every failed attempt up to BackoffMax and
+// adds up to 10% of jitter so that retries of a burst spread out.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for i := 1; i < attempts && delay < d.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.cfg.BackoffMax)
+	return delay + rand.N(delay/10+1)
+}
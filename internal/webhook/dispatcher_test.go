@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"subscription-aggregator/internal/config"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const testSecret = "whsec_test-secret-value"
+
+var testConfig = config.WebhooksConfig{
+	Timeout:             5 * time.Second,
+	MaxAttempts:         3,
+	BackoffBase:         time.Minute,
+	BackoffMax:          3 * time.Minute,
+	AllowPrivateTargets: true,
+}
+
+type savedAttempt struct {
+	SQL    string
+	Status string
+}
+
+// useDryRunDB points repository.DB at a database that never connects and
+// records every saved attempt instead of running the UPDATE.
+func useDryRunDB(t *testing.T) *[]savedAttempt {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var saved []savedAttempt
+	err = db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		delivery := tx.Statement.Dest.(*model.WebhookDelivery)
+		saved = append(saved, savedAttempt{SQL: tx.Statement.SQL.String(), Status: delivery.Status})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := repository.DB
+	repository.DB = db
+	t.Cleanup(func() { repository.DB = previous })
+	return &saved
+}
+
+func newDelivery(attempts int) *model.WebhookDelivery {
+	return &model.WebhookDelivery{
+		ID:        42,
+		WebhookID: 7,
+		EventID:   uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		EventType: "subscription.created",
+		Payload:   model.JSON(`{"id":1}`),
+		Status:    model.DeliveryPending,
+		Attempts:  attempts,
+	}
+}
+
+func endpointFor(url string) model.WebhookEndpoint {
+	return model.WebhookEndpoint{ID: 7, URL: url, Secret: testSecret}
+}
+
+func TestAttemptSendsSignedRequest(t *testing.T) {
+	saved := useDryRunDB(t)
+
+	var received http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := newDelivery(0)
+	NewDispatcher(testConfig).attempt(context.Background(), delivery, endpointFor(server.URL))
+
+	if received == nil {
+		t.Fatal("endpoint was not called")
+	}
+	if string(body) != `{"id":1}` {
+		t.Errorf("body = %s", body)
+	}
+	for header, want := range map[string]string{
+		"Content-Type": "application/json",
+		EventHeader:    "subscription.created",
+		EventIDHeader:  "22222222-2222-2222-2222-222222222222",
+		DeliveryHeader: "42",
+	} {
+		if got := received.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	signature := received.Get(SignatureHeader)
+	timestamp, _, _ := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("malformed signature %q", signature)
+	}
+	if want := Sign(testSecret, time.Unix(unix, 0), body); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+	if signature == Sign("another-secret", time.Unix(unix, 0), body) {
+		t.Error("signature does not depend on the secret")
+	}
+
+	if delivery.Status != model.DeliverySucceeded || delivery.Attempts != 1 || delivery.DeliveredAt == nil || delivery.NextAttemptAt != nil {
+		t.Errorf("delivery = %+v, want succeeded after 1 attempt", delivery)
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("response status = %v, want 204", delivery.ResponseStatus)
+	}
+	if len(*saved) != 1 || (*saved)[0].Status != model.DeliverySucceeded {
+		t.Fatalf("saved attempts = %v, want one succeeded", *saved)
+	}
+	if sql := (*saved)[0].SQL; !strings.Contains(sql, `"delivered_at"`) || !strings.Contains(sql, `"response_status"`) {
+		t.Errorf("attempt saved without its outcome: %s", sql)
+	}
+}
+
+func TestAttemptRetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, "internal details")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		attempts    int
+		wantStatus  string
+		wantBackoff time.Duration
+	}{
+		{"first failure", 0, model.DeliveryPending, time.Minute},
+		{"second failure doubles the delay", 1, model.DeliveryPending, 2 * time.Minute},
+		{"last attempt", 2, model.DeliveryFailed, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := useDryRunDB(t)
+			delivery := newDelivery(tt.attempts)
+			before := time.Now()
+			NewDispatcher(testConfig).attempt(context.Background(), delivery, endpointFor(server.URL))
+
+			if delivery.Status != tt.wantStatus || delivery.Attempts != tt.attempts+1 {
+				t.Fatalf("status = %s after %d attempts, want %s after %d", delivery.Status, delivery.Attempts, tt.wantStatus, tt.attempts+1)
+			}
+			if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusInternalServerError {
+				t.Errorf("response status = %v, want 500", delivery.ResponseStatus)
+			}
+			if delivery.LastError != "unexpected status 500" {
+				t.Errorf("last error = %q", delivery.LastError)
+			}
+			if len(*saved) != 1 {
+				t.Errorf("saved %d attempts, want 1", len(*saved))
+			}
+
+			if tt.wantBackoff == 0 {
+				if delivery.NextAttemptAt != nil {
+					t.Errorf("failed delivery rescheduled at %v", delivery.NextAttemptAt)
+				}
+				return
+			}
+			if delivery.NextAttemptAt == nil {
+				t.Fatal("delivery not rescheduled")
+			}
+			delay := delivery.NextAttemptAt.Sub(before)
+			if delay < tt.wantBackoff || delay > tt.wantBackoff+tt.wantBackoff/10+time.Second {
+				t.Errorf("next attempt in %v, want %v plus up to 10%% jitter", delay, tt.wantBackoff)
+			}
+		})
+	}
+	if got := calls.Load(); got != int32(len(tests)) {
+		t.Errorf("endpoint called %d times, want %d", got, len(tests))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(testConfig)
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 3 * time.Minute},
+		{10, 3 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.attempts), func(t *testing.T) {
+			got := d.backoff(tt.attempts)
+			if got < tt.want || got > tt.want+tt.want/10 {
+				t.Errorf("backoff(%d) = %v, want %v plus up to 10%% jitter", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttemptDoesNotFollowRedirects(t *testing.T) {
+	useDryRunDB(t)
+
+	var followed atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		followed.Store(true)
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	delivery := newDelivery(0)
+	NewDispatcher(testConfig).attempt(context.Background(), delivery, endpointFor(server.URL))
+
+	if followed.Load() {
+		t.Error("redirect was followed")
+	}
+	if delivery.Status != model.DeliveryPending || delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusFound {
+		t.Errorf("delivery = %+v, want a failed attempt with status 302", delivery)
+	}
+}
+
+func TestAttemptRefusesPrivateAddress(t *testing.T) {
+	useDryRunDB(t)
+
+	var called atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called.Store(true)
+	}))
+	defer server.Close()
+
+	cfg := testConfig
+	cfg.AllowPrivateTargets = false
+	delivery := newDelivery(0)
+	NewDispatcher(cfg).attempt(context.Background(), delivery, endpointFor(server.URL))
+
+	if called.Load() {
+		t.Error("loopback endpoint was called")
+	}
+	if delivery.Status != model.DeliveryFailed || delivery.NextAttemptAt != nil {
+		t.Errorf("delivery = %+v, want failed permanently", delivery)
+	}
+	if !strings.Contains(delivery.LastError, ErrForbiddenAddress.Error()) {
+		t.Errorf("last error = %q, want %q", delivery.LastError, ErrForbiddenAddress)
+	}
+}
+
+func TestAttemptDisabledEndpoint(t *testing.T) {
+	useDryRunDB(t)
+
+	disabled := time.Now()
+	endpoint := endpointFor("http://127.0.0.1:1/")
+	endpoint.DisabledAt = &disabled
+	delivery := newDelivery(0)
+	NewDispatcher(testConfig).attempt(context.Background(), delivery, endpoint)
+
+	if delivery.Status != model.DeliveryFailed || delivery.LastError != errDisabled.Error() {
+		t.Errorf("delivery = %+v, want failed with %q", delivery, errDisabled)
+	}
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"subscription-aggregator/internal/event"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	monthyear "subscription-aggregator/pkg/month-year"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const renewalCheckInterval = time.Hour
+
+// renewalNamespace derives event IDs from the subscription and the month, so
+// that a restart or a second instance queues the same events again and the
+// unique (webhook_id, event_id) index drops them.
+var renewalNamespace = uuid.MustParse("6f1d3c52-9a0e-4c57-8f7b-2b1f4c9e0d3a")
+
+type renewal struct {
+	model.Subscription
+	RenewalDate monthyear.MonthYear `json:"renewal_date"`
+}
+
+// RenewalNotifier emits subscription.renewal_upcoming for every subscription
+// that continues into the next month once the month is less than lead away.
+type RenewalNotifier struct {
+	lead     time.Duration
+	notified monthyear.MonthYear
+}
+
+func NewRenewalNotifier(lead time.Duration) *RenewalNotifier {
+	return &RenewalNotifier{lead: lead}
+}
+
+func (n *RenewalNotifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(renewalCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		n.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *RenewalNotifier) check(ctx context.Context) {
+	month := monthyear.Current().AddMonths(1)
+	if month == n.notified || time.Until(month.Time) > n.lead {
+		return
+	}
+
+	log := slog.With("month", month.String())
+	count := 0
+	err := repository.EachUpcomingRenewal(ctx, month, func(subs []model.Subscription) error {
+		events := make([]event.Event, 0, len(subs))
+		for _, sub := range subs {
+			e, err := event.New(event.SubscriptionRenewalUpcoming, sub.TenantID, renewal{Subscription: sub, RenewalDate: month})
+			if err != nil {
+				return err
+			}
+			e.ID = uuid.NewSHA1(renewalNamespace, []byte(strconv.FormatUint(uint64(sub.ID), 10)+":"+month.String()))
+			events = append(events, e)
+		}
+		count += len(events)
+		return repository.EnqueueWebhookEvents(ctx, events...)
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error("failed to queue renewal notifications", "error", err)
+		}
+		return
+	}
+
+	n.notified = month
+	log.Info("queued renewal notifications", "subscriptions", count)
+}
@@ -0,0 +1,104 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+const maxLineOctets = 75
+
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	RRule       string
+}
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+func (cal Calendar) WriteTo(w io.Writer) (int64, error) {
+	cw := &contentWriter{w: bufio.NewWriter(w)}
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:" + cal.ProdID)
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		cw.line("X-WR-CALNAME:" + escapeText(cal.Name))
+	}
+	for _, event := range cal.Events {
+		cw.line("BEGIN:VEVENT")
+		cw.line("UID:" + event.UID)
+		cw.line("DTSTAMP:" + stamp)
+		cw.line("DTSTART;VALUE=DATE:" + event.Start.Format("20060102"))
+		if event.RRule != "" {
+			cw.line("RRULE:" + event.RRule)
+		}
+		cw.line("SUMMARY:" + escapeText(event.Summary))
+		if event.Description != "" {
+			cw.line("DESCRIPTION:" + escapeText(event.Description))
+		}
+		cw.line("TRANSP:TRANSPARENT")
+		cw.line("END:VEVENT")
+	}
+	cw.line("END:VCALENDAR")
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+type contentWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+// line writes a content line terminated by CRLF, folding it into continuation
+// lines so that none exceeds 75 octets without splitting a UTF-8 sequence.
+func (cw *contentWriter) line(s string) {
+	if cw.err != nil {
+		return
+	}
+
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		cw.write(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1
+	}
+	cw.write(s + "\r\n")
+}
+
+func (cw *contentWriter) write(s string) {
+	if cw.err != nil {
+		return
+	}
+	n, err := cw.w.WriteString(s)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
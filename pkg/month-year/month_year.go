@@ -7,13 +7,40 @@ import (
 	"time"
 )
 
+const Layout = "01-2006"
+
 type MonthYear struct {
 	time.Time
 }
 
+func Parse(s string) (MonthYear, error) {
+	t, err := time.Parse(Layout, s)
+	if err != nil {
+		return MonthYear{}, err
+	}
+	return MonthYear{Time: t}, nil
+}
+
+func Current() MonthYear {
+	now := time.Now().UTC()
+	return MonthYear{Time: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (my MonthYear) AddMonths(n int) MonthYear {
+	return MonthYear{Time: my.AddDate(0, n, 0)}
+}
+
+func (my MonthYear) MonthsUntil(other MonthYear) int {
+	return (other.Year()-my.Year())*12 + int(other.Month()-my.Month())
+}
+
+func (my MonthYear) String() string {
+	return my.Format(Layout)
+}
+
 func (my *MonthYear) UnmarshalJSON(b []byte) error {
 	s := strings.Trim(string(b), `"`)
-	t, err := time.Parse("01-2006", s)
+	t, err := time.Parse(Layout, s)
 	if err != nil {
 		return err
 	}
@@ -22,7 +49,7 @@ func (my *MonthYear) UnmarshalJSON(b []byte) error {
 }
 
 func (my MonthYear) MarshalJSON() ([]byte, error) {
-	return []byte(`"` + my.Format("01-2006") + `"`), nil
+	return []byte(`"` + my.Format(Layout) + `"`), nil
 }
 
 func (my MonthYear) Value() (driver.Value, error) {
@@ -5,10 +5,13 @@ import (
 )
 
 type SubscriptionExample struct {
-	ServiceName string    `json:"service_name" example:"Netflix"`
-	Price       uint      `json:"price"        example:"999"`
-	UserID      uuid.UUID `json:"user_id"      example:"11111111-1111-1111-1111-111111111111"`
-	StartDate   string    `json:"start_date"   example:"07-2025"`
+	ServiceName  string    `json:"service_name"            example:"Netflix"`
+	Category     string    `json:"category,omitempty"      example:"entertainment"`
+	Price        uint      `json:"price"                   example:"999"`
+	UserID       uuid.UUID `json:"user_id"                 example:"11111111-1111-1111-1111-111111111111"`
+	StartDate    string    `json:"start_date"              example:"07-2025"`
+	EndDate      string    `json:"end_date,omitempty"      example:"12-2025"`
+	BillingCycle string    `json:"billing_cycle,omitempty" example:"monthly" enums:"monthly,quarterly,yearly"`
 }
 
 type UpdateSubscriptionExample struct {
@@ -17,30 +20,330 @@ type UpdateSubscriptionExample struct {
 }
 
 type SubscriptionResponse struct {
-	ID          uint      `json:"id"           example:"1"`
-	ServiceName string    `json:"service_name" example:"Netflix"`
-	Price       uint      `json:"price"        example:"999"`
-	UserID      uuid.UUID `json:"user_id"      example:"11111111-1111-1111-1111-111111111111"`
-	StartDate   string    `json:"start_date"   example:"07-2025"`
+	ID           uint      `json:"id"                 example:"1"`
+	ServiceName  string    `json:"service_name"       example:"Netflix"`
+	Category     string    `json:"category,omitempty" example:"entertainment"`
+	Price        uint      `json:"price"              example:"999"`
+	UserID       uuid.UUID `json:"user_id"            example:"11111111-1111-1111-1111-111111111111"`
+	StartDate    string    `json:"start_date"         example:"07-2025"`
+	EndDate      string    `json:"end_date,omitempty" example:"12-2025"`
+	BillingCycle string    `json:"billing_cycle"      example:"monthly" enums:"monthly,quarterly,yearly"`
 }
 
 type ErrorResponse400 struct {
 	Error string `json:"error" example:"invalid {id/request/json}"`
 }
 
+type ErrorResponse401 struct {
+	Error string `json:"error" example:"authentication required"`
+}
+
+type ErrorResponse403 struct {
+	Error string `json:"error" example:"admin access required"`
+}
+
 type ErrorResponse404 struct {
 	Error string `json:"error" example:"record not found in db"`
 }
 
+type ErrorResponse409 struct {
+	Error string `json:"error" example:"overlapping active subscription exists"`
+	ID    uint   `json:"id"    example:"1"`
+}
+
+type ErrorResponse413 struct {
+	Error string `json:"error" example:"file is too large"`
+}
+
+type ErrorResponse429 struct {
+	Error string `json:"error" example:"rate limit exceeded"`
+}
+
 type ErrorResponse500 struct {
 	Error string `json:"error" example:"failed to {create/find/update/delete} record in db"`
 }
 
+type ErrorResponse503 struct {
+	Error string `json:"error" example:"event stream unavailable"`
+}
+
 type MessageResponse struct {
 	Message string `json:"message"      example:"{created/updated/deleted}"`
 	ID      uint   `json:"id,omitempty" example:"1"`
 }
 
 type SumResponse struct {
-	SumPrice int `json:"sum_price" example:"999"`
+	SumPrice int    `json:"sum_price" example:"999"`
+	Currency string `json:"currency"  example:"RUB"`
+}
+
+type BudgetExample struct {
+	UserID           uuid.UUID `json:"user_id"                     example:"11111111-1111-1111-1111-111111111111"`
+	ServiceName      string    `json:"service_name,omitempty"      example:"Netflix"`
+	Category         string    `json:"category,omitempty"          example:""`
+	Amount           uint      `json:"amount"                      example:"1500"`
+	WarningThreshold uint      `json:"warning_threshold,omitempty" example:"80"`
+}
+
+type BudgetResponse struct {
+	ID               uint      `json:"id"                     example:"1"`
+	UserID           uuid.UUID `json:"user_id"                example:"11111111-1111-1111-1111-111111111111"`
+	ServiceName      string    `json:"service_name,omitempty" example:"Netflix"`
+	Category         string    `json:"category,omitempty"     example:""`
+	Amount           uint      `json:"amount"                 example:"1500"`
+	WarningThreshold uint      `json:"warning_threshold"      example:"80"`
+}
+
+type BudgetMonthResponse struct {
+	Month     string `json:"month"               example:"12-2025"`
+	Spend     int    `json:"spend"               example:"1299"`
+	Projected int    `json:"projected,omitempty" example:"1450"`
+	Status    string `json:"status"              example:"warning" enums:"ok,warning,exceeded"`
+	Forecast  bool   `json:"forecast"            example:"true"`
+}
+
+type BudgetEvaluationResponse struct {
+	Budget BudgetResponse        `json:"budget"`
+	Months []BudgetMonthResponse `json:"months"`
+}
+
+type DuplicateResponse struct {
+	Service   string               `json:"service"   example:"netflix"`
+	Original  SubscriptionResponse `json:"original"`
+	Duplicate SubscriptionResponse `json:"duplicate"`
+}
+
+type ServicePriceResponse struct {
+	ID          uint   `json:"id"           example:"1"`
+	ServiceName string `json:"service_name" example:"Netflix"`
+	Price       int    `json:"price"        example:"999"`
+	StartDate   string `json:"start_date"   example:"07-2025"`
+	Months      int    `json:"months"       example:"4"`
+}
+
+type MonthOverMonthResponse struct {
+	PreviousSpend int     `json:"previous_spend" example:"999"`
+	CurrentSpend  int     `json:"current_spend"  example:"1398"`
+	Change        int     `json:"change"         example:"399"`
+	ChangePercent float64 `json:"change_percent" example:"39.94"`
+}
+
+type UserStatsResponse struct {
+	UserID              uuid.UUID              `json:"user_id"               example:"11111111-1111-1111-1111-111111111111"`
+	Month               string                 `json:"month"                 example:"10-2025"`
+	ActiveCount         int                    `json:"active_count"          example:"2"`
+	AverageMonthlySpend float64                `json:"average_monthly_spend" example:"1198.5"`
+	MostExpensive       ServicePriceResponse   `json:"most_expensive"`
+	MonthOverMonth      MonthOverMonthResponse `json:"month_over_month"`
+	LongestRunning      ServicePriceResponse   `json:"longest_running"`
+	LifetimeSpend       int                    `json:"lifetime_spend"        example:"4794"`
+	Currency            string                 `json:"currency"              example:"RUB"`
+}
+
+type ServiceMonthStatsResponse struct {
+	Month       string `json:"month"        example:"07-2025"`
+	ServiceName string `json:"service_name" example:"netflix"`
+	Subscribers int    `json:"subscribers"  example:"42"`
+	Revenue     int    `json:"revenue"      example:"41958"`
+}
+
+type ServicePriceStatsResponse struct {
+	ServiceName   string  `json:"service_name"  example:"netflix"`
+	Subscriptions int     `json:"subscriptions" example:"42"`
+	AveragePrice  float64 `json:"average_price" example:"949.5"`
+	MinPrice      int     `json:"min_price"     example:"599"`
+	MaxPrice      int     `json:"max_price"     example:"1299"`
+}
+
+type MonthMovementResponse struct {
+	Month            string `json:"month"             example:"07-2025"`
+	NewSubscriptions int    `json:"new_subscriptions" example:"12"`
+	Churned          int    `json:"churned"           example:"3"`
+}
+
+type CohortResponse struct {
+	Month     string    `json:"month"     example:"01-2025"`
+	Size      int       `json:"size"      example:"40"`
+	Retention []float64 `json:"retention" example:"0.95,0.8,0.6"`
+}
+
+type CohortReportResponse struct {
+	ServiceName string           `json:"service_name" example:"Netflix"`
+	Offsets     []int            `json:"offsets"      example:"1,3,6,12"`
+	Cohorts     []CohortResponse `json:"cohorts"`
+}
+
+type PriceAnomalyResponse struct {
+	Month       string    `json:"month"        example:"08-2025"`
+	ID          uint      `json:"id"           example:"7"`
+	UserID      uuid.UUID `json:"user_id"      example:"11111111-1111-1111-1111-111111111111"`
+	ServiceName string    `json:"service_name" example:"Netflix"`
+	Price       int       `json:"price"        example:"99900"`
+	StartDate   string    `json:"start_date"   example:"07-2025"`
+	Median      float64   `json:"median"       example:"999"`
+	SampleSize  int       `json:"sample_size"  example:"42"`
+	Ratio       float64   `json:"ratio"        example:"100"`
+}
+
+type PriceAnomalyReportResponse struct {
+	Factor    float64                `json:"factor"     example:"5"`
+	MinSample int                    `json:"min_sample" example:"3"`
+	Anomalies []PriceAnomalyResponse `json:"anomalies"`
+}
+
+type ImportRowErrorResponse struct {
+	Row   int    `json:"row"   example:"3"`
+	Error string `json:"error" example:"invalid start_date"`
+}
+
+type ImportReportResponse struct {
+	DryRun   bool                     `json:"dry_run"  example:"false"`
+	Mode     string                   `json:"mode"     example:"transaction"`
+	Total    int                      `json:"total"    example:"1000"`
+	Valid    int                      `json:"valid"    example:"999"`
+	Inserted int                      `json:"inserted" example:"0"`
+	Errors   []ImportRowErrorResponse `json:"errors"`
+}
+
+type CalendarTokenResponse struct {
+	Token string `json:"token" example:"3f7c2a9e..."`
+	URL   string `json:"url"   example:"/users/11111111-1111-1111-1111-111111111111/calendar.ics?token=3f7c2a9e..."`
+}
+
+type BulkCreateExample struct {
+	Subscriptions []SubscriptionExample `json:"subscriptions"`
+}
+
+type BulkUpdateFilterExample struct {
+	UserID      string `json:"user_id,omitempty" example:"11111111-1111-1111-1111-111111111111"`
+	ServiceName string `json:"service_name"      example:"Netflix"`
+}
+
+type BulkUpdateChangesExample struct {
+	ServiceName string `json:"service_name,omitempty" example:"Netflix"`
+	Price       uint   `json:"price,omitempty"        example:"1099"`
+	StartDate   string `json:"start_date,omitempty"   example:"07-2025"`
+	EndDate     string `json:"end_date,omitempty"     example:"12-2025"`
+}
+
+type BulkUpdateExample struct {
+	Filter  BulkUpdateFilterExample  `json:"filter"`
+	Changes BulkUpdateChangesExample `json:"changes"`
+}
+
+type BulkDeleteExample struct {
+	IDs []uint `json:"ids" example:"1,2,3"`
+}
+
+type BulkItemResultResponse struct {
+	Index  int    `json:"index"           example:"0"`
+	ID     uint   `json:"id,omitempty"    example:"1"`
+	Status string `json:"status"          example:"created" enums:"valid,invalid,created,matched,updated,deleted,not_found"`
+	Error  string `json:"error,omitempty" example:"end_date is before start_date"`
+}
+
+type BulkResultResponse struct {
+	DryRun  bool                     `json:"dry_run" example:"false"`
+	Count   int                      `json:"count"   example:"3"`
+	Results []BulkItemResultResponse `json:"results"`
+}
+
+type HealthCheckResponse struct {
+	Status    string  `json:"status"          example:"ok" enums:"ok,fail"`
+	LatencyMS float64 `json:"latency_ms"      example:"0.42"`
+	Error     string  `json:"error,omitempty" example:"context deadline exceeded"`
+}
+
+type HealthResponse struct {
+	Status string                         `json:"status" example:"ok" enums:"ok,unavailable"`
+	Checks map[string]HealthCheckResponse `json:"checks"`
+}
+
+type APIKeyExample struct {
+	Name      string    `json:"name"                 example:"billing-service"`
+	Scopes    []string  `json:"scopes"               example:"read,write"`
+	UserID    uuid.UUID `json:"user_id,omitempty"    example:"11111111-1111-1111-1111-111111111111"`
+	ExpiresAt string    `json:"expires_at,omitempty" example:"2026-12-31T00:00:00Z"`
+}
+
+type APIKeyResponse struct {
+	ID         uint      `json:"id"                     example:"1"`
+	CreatedAt  string    `json:"created_at"             example:"2025-07-01T12:00:00Z"`
+	Name       string    `json:"name"                   example:"billing-service"`
+	Prefix     string    `json:"prefix"                 example:"sak_q3Xk9aB1"`
+	Scopes     []string  `json:"scopes"                 example:"read,write"`
+	UserID     uuid.UUID `json:"user_id,omitempty"      example:"11111111-1111-1111-1111-111111111111"`
+	ExpiresAt  string    `json:"expires_at,omitempty"   example:"2026-12-31T00:00:00Z"`
+	LastUsedAt string    `json:"last_used_at,omitempty" example:"2025-07-02T08:30:00Z"`
+	RevokedAt  string    `json:"revoked_at,omitempty"   example:"2025-07-03T10:00:00Z"`
+}
+
+type APIKeyIssuedResponse struct {
+	Message string `json:"message" example:"created"`
+	ID      uint   `json:"id"      example:"1"`
+	Key     string `json:"key"     example:"sak_q3Xk9aB1mZ0pLr7sT2vW4yN6cE8gJ5hK1dF3aQ9xU0o"`
+	Prefix  string `json:"prefix"  example:"sak_q3Xk9aB1"`
+}
+
+type TenantSettingsExample struct {
+	Name            string `json:"name"             example:"Acme"`
+	DefaultCurrency string `json:"default_currency" example:"RUB"`
+}
+
+type TenantExample struct {
+	ID              uuid.UUID `json:"id,omitempty"     example:"22222222-2222-2222-2222-222222222222"`
+	Name            string    `json:"name"             example:"Acme"`
+	DefaultCurrency string    `json:"default_currency" example:"RUB"`
+}
+
+type TenantResponse struct {
+	ID              uuid.UUID `json:"id"               example:"22222222-2222-2222-2222-222222222222"`
+	CreatedAt       string    `json:"created_at"       example:"2025-07-01T12:00:00Z"`
+	Name            string    `json:"name"             example:"Acme"`
+	DefaultCurrency string    `json:"default_currency" example:"RUB"`
+}
+
+type WebhookExample struct {
+	URL    string   `json:"url"              example:"https://example.com/hooks/subscriptions"`
+	Events []string `json:"events"           example:"subscription.created,subscription.deleted"`
+	Secret string   `json:"secret,omitempty" example:"my-webhook-signing-secret"`
+}
+
+type WebhookResponse struct {
+	ID         uint     `json:"id"                    example:"1"`
+	CreatedAt  string   `json:"created_at"            example:"2025-07-01T12:00:00Z"`
+	URL        string   `json:"url"                   example:"https://example.com/hooks/subscriptions"`
+	Events     []string `json:"events"                example:"subscription.created,subscription.deleted"`
+	DisabledAt string   `json:"disabled_at,omitempty" example:"2025-07-03T10:00:00Z"`
+}
+
+type WebhookCreatedResponse struct {
+	Message string `json:"message" example:"created"`
+	ID      uint   `json:"id"      example:"1"`
+	Secret  string `json:"secret"  example:"whsec_3q2-7wR1x0yZb4TfK9mN2pLc8vA5sD6gH1jE0uI7oQk"`
+}
+
+type WebhookEventResponse struct {
+	ID         uuid.UUID            `json:"id"          example:"6b0e1f5a-3c2d-4e8f-9a7b-1c2d3e4f5a6b"`
+	Type       string               `json:"type"        example:"subscription.created"`
+	OccurredAt string               `json:"occurred_at" example:"2025-07-01T12:00:00Z"`
+	Data       SubscriptionResponse `json:"data"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint                 `json:"id"                        example:"1"`
+	CreatedAt      string               `json:"created_at"                example:"2025-07-01T12:00:00Z"`
+	UpdatedAt      string               `json:"updated_at"                example:"2025-07-01T12:00:01Z"`
+	WebhookID      uint                 `json:"webhook_id"                example:"1"`
+	EventID        uuid.UUID            `json:"event_id"                  example:"6b0e1f5a-3c2d-4e8f-9a7b-1c2d3e4f5a6b"`
+	EventType      string               `json:"event_type"                example:"subscription.created"`
+	Payload        WebhookEventResponse `json:"payload"`
+	Status         string               `json:"status"                    example:"pending"`
+	Attempts       int                  `json:"attempts"                  example:"2"`
+	NextAttemptAt  string               `json:"next_attempt_at,omitempty" example:"2025-07-01T12:01:01Z"`
+	LastAttemptAt  string               `json:"last_attempt_at,omitempty" example:"2025-07-01T12:00:01Z"`
+	ResponseStatus int                  `json:"response_status,omitempty" example:"503"`
+	LastError      string               `json:"last_error,omitempty"      example:"unexpected status 503"`
+	DeliveredAt    string               `json:"delivered_at,omitempty"    example:"2025-07-01T12:05:00Z"`
+	RedeliveryOf   uint                 `json:"redelivery_of,omitempty"   example:"1"`
 }
@@ -0,0 +1,136 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const contentTypeSheet = "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"
+
+// Writer streams a minimal XLSX workbook. Sheets are written one after another
+// and rows are flushed as they are added, so the workbook never sits in memory.
+type Writer struct {
+	zw     *zip.Writer
+	sheet  *bufio.Writer
+	sheets []string
+	row    int
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zw: zip.NewWriter(w)}
+}
+
+func (w *Writer) AddSheet(name string) error {
+	if err := w.closeSheet(); err != nil {
+		return err
+	}
+
+	w.sheets = append(w.sheets, name)
+	f, err := w.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(w.sheets)))
+	if err != nil {
+		return err
+	}
+
+	w.sheet = bufio.NewWriter(f)
+	w.row = 0
+	_, err = w.sheet.WriteString(xml.Header +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return err
+}
+
+func (w *Writer) WriteRow(cells ...interface{}) error {
+	if w.sheet == nil {
+		return fmt.Errorf("xlsx: no sheet added")
+	}
+
+	w.row++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.row)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(w.row)
+		switch v := cell.(type) {
+		case int, int64, uint, uint64, float64:
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>%v</v></c>`, ref, v)
+		default:
+			fmt.Fprintf(w.sheet, `<c r="%s" t="inlineStr"><is><t>`, ref)
+			if err := xml.EscapeText(w.sheet, []byte(fmt.Sprint(v))); err != nil {
+				return err
+			}
+			w.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (w *Writer) Close() error {
+	if err := w.closeSheet(); err != nil {
+		return err
+	}
+
+	var contentTypes, workbook, rels strings.Builder
+	for i, name := range w.sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes,
+			`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="%s"/>`, n, contentTypeSheet)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(name), n, n)
+		fmt.Fprintf(&rels,
+			`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`,
+			n, n)
+	}
+
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			contentTypes.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` +
+			workbook.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+	}
+	for _, file := range files {
+		f, err := w.zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header+file.body); err != nil {
+			return err
+		}
+	}
+
+	return w.zw.Close()
+}
+
+func (w *Writer) closeSheet() error {
+	if w.sheet == nil {
+		return nil
+	}
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	err := w.sheet.Flush()
+	w.sheet = nil
+	return err
+}
+
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}